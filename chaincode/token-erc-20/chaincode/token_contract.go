@@ -30,21 +30,21 @@ type event struct {
 
 // Mint creates new tokens and adds them to minter's account balance
 // This function triggers a Transfer event
-func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount int) error {
+func (s *SmartContract) Mint(ctx TransactionContextInterface, amount int) error {
 
 	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to mint new tokens
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	clientMSPID, err := ctx.GetCallerMSPID()
 	if err != nil {
-		return fmt.Errorf("failed to get MSPID: %v", err)
+		return err
 	}
 	if clientMSPID != "Org1MSP" {
 		return fmt.Errorf("client is not authorized to mint new tokens")
 	}
 
 	// Get ID of submitting client identity
-	minter, err := ctx.GetClientIdentity().GetID()
+	minter, err := ctx.GetCallerID()
 	if err != nil {
-		return fmt.Errorf("failed to get client id: %v", err)
+		return err
 	}
 
 	if amount <= 0 {
@@ -112,21 +112,21 @@ func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount
 
 // Burn redeems tokens the minter's account balance
 // This function triggers a Transfer event
-func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, amount int) error {
+func (s *SmartContract) Burn(ctx TransactionContextInterface, amount int) error {
 
 	// Check minter authorization - this sample assumes Org1 is the central banker with privilege to burn new tokens
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	clientMSPID, err := ctx.GetCallerMSPID()
 	if err != nil {
-		return fmt.Errorf("failed to get MSPID: %v", err)
+		return err
 	}
 	if clientMSPID != "Org1MSP" {
 		return fmt.Errorf("client is not authorized to mint new tokens")
 	}
 
 	// Get ID of submitting client identity
-	minter, err := ctx.GetClientIdentity().GetID()
+	minter, err := ctx.GetCallerID()
 	if err != nil {
-		return fmt.Errorf("failed to get client id: %v", err)
+		return err
 	}
 
 	if amount <= 0 {
@@ -193,12 +193,12 @@ func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, amount
 // Transfer transfers tokens from client account to recipient account
 // recipient account must be a valid clientID as returned by the ClientID() function
 // This function triggers a Transfer event
-func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, recipient string, amount int) error {
+func (s *SmartContract) Transfer(ctx TransactionContextInterface, recipient string, amount int) error {
 
 	// Get ID of submitting client identity
-	clientID, err := ctx.GetClientIdentity().GetID()
+	clientID, err := ctx.GetCallerID()
 	if err != nil {
-		return fmt.Errorf("failed to get client id: %v", err)
+		return err
 	}
 
 	err = transferHelper(ctx, clientID, recipient, amount)
@@ -221,7 +221,7 @@ func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, re
 }
 
 // BalanceOf returns the balance of the given account
-func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string) (int, error) {
+func (s *SmartContract) BalanceOf(ctx TransactionContextInterface, account string) (int, error) {
 	balanceBytes, err := ctx.GetStub().GetState(account)
 	if err != nil {
 		return 0, fmt.Errorf("failed to read from world state: %v", err)
@@ -236,12 +236,12 @@ func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, a
 }
 
 // ClientAccountBalance returns the balance of the requesting client's account
-func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextInterface) (int, error) {
+func (s *SmartContract) ClientAccountBalance(ctx TransactionContextInterface) (int, error) {
 
 	// Get ID of submitting client identity
-	clientID, err := ctx.GetClientIdentity().GetID()
+	clientID, err := ctx.GetCallerID()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get client id: %v", err)
+		return 0, err
 	}
 
 	balanceBytes, err := ctx.GetStub().GetState(clientID)
@@ -260,19 +260,19 @@ func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextI
 // ClientAccountID returns the id of the requesting client's account
 // In this implementation, the client account ID is the clientId itself
 // Users can use this function to get their own account id, which they can then give to others as the payment address
-func (s *SmartContract) ClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
+func (s *SmartContract) ClientAccountID(ctx TransactionContextInterface) (string, error) {
 
 	// Get ID of submitting client identity
-	clientAccountID, err := ctx.GetClientIdentity().GetID()
+	clientAccountID, err := ctx.GetCallerID()
 	if err != nil {
-		return "", fmt.Errorf("failed to get client id: %v", err)
+		return "", err
 	}
 
 	return clientAccountID, nil
 }
 
 // TotalSupply returns the total token supply
-func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface) (int, error) {
+func (s *SmartContract) TotalSupply(ctx TransactionContextInterface) (int, error) {
 
 	// Retrieve total supply of tokens from state of smart contract
 	totalSupplyBytes, err := ctx.GetStub().GetState(totalSupplyKey)
@@ -297,12 +297,12 @@ func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface)
 // Approve allows the spender to withdraw from the calling client's token account
 // The spender can withdraw multiple times if necessary, up to the value amount
 // This function triggers an Approval event
-func (s *SmartContract) Approve(ctx contractapi.TransactionContextInterface, spender string, value int) error {
+func (s *SmartContract) Approve(ctx TransactionContextInterface, spender string, value int) error {
 
 	// Get ID of submitting client identity
-	owner, err := ctx.GetClientIdentity().GetID()
+	owner, err := ctx.GetCallerID()
 	if err != nil {
-		return fmt.Errorf("failed to get client id: %v", err)
+		return err
 	}
 
 	// Create allowanceKey
@@ -334,7 +334,7 @@ func (s *SmartContract) Approve(ctx contractapi.TransactionContextInterface, spe
 }
 
 // Allowance returns the amount still available for the spender to withdraw from the owner
-func (s *SmartContract) Allowance(ctx contractapi.TransactionContextInterface, owner string, spender string) (int, error) {
+func (s *SmartContract) Allowance(ctx TransactionContextInterface, owner string, spender string) (int, error) {
 
 	// Create allowanceKey
 	allowanceKey, err := ctx.GetStub().CreateCompositeKey(allowancePrefix, []string{owner, spender})
@@ -364,12 +364,12 @@ func (s *SmartContract) Allowance(ctx contractapi.TransactionContextInterface, o
 
 // TransferFrom transfers the value amount from the "from" address to the "to" address
 // This function triggers a Transfer event
-func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, value int) error {
+func (s *SmartContract) TransferFrom(ctx TransactionContextInterface, from string, to string, value int) error {
 
 	// Get ID of submitting client identity
-	spender, err := ctx.GetClientIdentity().GetID()
+	spender, err := ctx.GetCallerID()
 	if err != nil {
-		return fmt.Errorf("failed to get client id: %v", err)
+		return err
 	}
 
 	// Create allowanceKey
@@ -425,7 +425,7 @@ func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface
 
 // transferHelper is a helper function that transfers tokens from the "from" address to the "to" address
 // Dependant functions include Transfer and TransferFrom
-func transferHelper(ctx contractapi.TransactionContextInterface, from string, to string, value int) error {
+func transferHelper(ctx TransactionContextInterface, from string, to string, value int) error {
 
 	if from == to {
 		return fmt.Errorf("cannot transfer to and from same client account")
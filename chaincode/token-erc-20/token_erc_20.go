@@ -13,7 +13,10 @@ import (
 )
 
 func main() {
-	tokenChaincode, err := contractapi.NewChaincode(&chaincode.SmartContract{})
+	tokenContract := new(chaincode.SmartContract)
+	tokenContract.TransactionContextHandler = new(chaincode.TransactionContext)
+
+	tokenChaincode, err := contractapi.NewChaincode(tokenContract)
 	if err != nil {
 		log.Panicf("Error creating token-erc-20 chaincode: %v", err)
 	}
@@ -0,0 +1,194 @@
+// Command metadatagen generates, from the transaction function signatures of package
+// smartcontract, the parameter bindings validateParameters checks and the contract
+// metadata published by org.hyperledger.fabric:GetMetadata, with the validation rules as
+// parameter schemas. contractapi reads the metadata from contract-metadata/metadata.json
+// next to the chaincode binary, which the peer builds without the rest of the package, so
+// the metadata is also compiled in for smartcontract.InstallContractMetadata to write out
+// at startup. Run through go generate in the smartcontract directory
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+)
+
+// contractName is the name contractapi gives SmartContract in the metadata
+const contractName = "SmartContract"
+
+func main() {
+	source := flag.String("source", ".", "directory of package smartcontract")
+	metadataPath := flag.String("metadata", "../contract-metadata/metadata.json", "contract metadata to write")
+	bindingsPath := flag.String("bindings", "parameters_generated.go", "Go file of parameter bindings to write")
+	embeddedPath := flag.String("embedded", "metadata_generated.go", "Go file of the compiled in metadata to write")
+	flag.Parse()
+
+	parameters, err := transactionParameters(*source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = writeBindings(*bindingsPath, parameters)
+	if err != nil {
+		log.Fatal(err)
+	}
+	metadataJson, err := writeMetadata(*metadataPath, parameters)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = writeEmbedded(*embeddedPath, metadataJson)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// transactionParameters returns the parameter names, after the transaction context, of
+// every exported method of SmartContract declared in dir
+func transactionParameters(dir string) (map[string][]string, error) {
+	packages, err := parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	parameters := map[string][]string{}
+	for _, file := range packages["smartcontract"].Files {
+		for _, decl := range file.Decls {
+			function, ok := decl.(*ast.FuncDecl)
+			if !ok || function.Recv == nil || !function.Name.IsExported() {
+				continue
+			}
+			receiver, ok := function.Recv.List[0].Type.(*ast.StarExpr)
+			if !ok || fmt.Sprint(receiver.X) != contractName {
+				continue
+			}
+			var names []string
+			for i, field := range function.Type.Params.List {
+				for _, name := range field.Names {
+					if i > 0 {
+						names = append(names, name.Name)
+					}
+				}
+			}
+			parameters[function.Name.Name] = names
+		}
+	}
+	return parameters, nil
+}
+
+func writeBindings(path string, parameters map[string][]string) error {
+	var functions []string
+	for function, names := range parameters {
+		for _, name := range names {
+			if smartcontract.ParameterField(function, name) != "" {
+				functions = append(functions, function)
+				break
+			}
+		}
+	}
+	sort.Strings(functions)
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by metadatagen from the transaction function signatures. DO NOT EDIT.\n\n")
+	out.WriteString("package smartcontract\n\n")
+	out.WriteString("// parameterFields binds the parameters of a transaction, in order, to the struct field\n")
+	out.WriteString("// whose rule they must satisfy, see parameterNames. Parameters bound to \"\" are not checked\n")
+	out.WriteString("var parameterFields = map[string][]string{\n")
+	for _, function := range functions {
+		var fields []string
+		for _, name := range parameters[function] {
+			fields = append(fields, fmt.Sprintf("%q", smartcontract.ParameterField(function, name)))
+		}
+		fmt.Fprintf(&out, "%q: {%s},\n", function, strings.Join(fields, ", "))
+	}
+	out.WriteString("}\n")
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, source, 0644)
+}
+
+// writeMetadata writes the metadata contractapi reflects from SmartContract, with the
+// parameters named as in the source and constrained by their validation rules, and
+// returns it
+func writeMetadata(path string, parameters map[string][]string) ([]byte, error) {
+	chaincode, err := contractapi.NewChaincode(smartcontract.NewSmartContract())
+	if err != nil {
+		return nil, err
+	}
+	stub := shimtest.NewMockStub("users", chaincode)
+	response := stub.MockInvoke("metadatagen", [][]byte{[]byte("org.hyperledger.fabric:GetMetadata")})
+	if response.Status != 200 {
+		return nil, fmt.Errorf("GetMetadata failed: %s", response.Message)
+	}
+	var chaincodeMetadata metadata.ContractChaincodeMetadata
+	err = json.Unmarshal(response.Payload, &chaincodeMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	contract := chaincodeMetadata.Contracts[contractName]
+	for _, transaction := range contract.Transactions {
+		names := parameters[transaction.Name]
+		if len(names) != len(transaction.Parameters) {
+			return nil, fmt.Errorf("%s has %d parameters in the source and %d in the metadata", transaction.Name, len(names), len(transaction.Parameters))
+		}
+		for i := range transaction.Parameters {
+			transaction.Parameters[i].Name = names[i]
+			smartcontract.ApplyParameterRule(&transaction.Parameters[i], smartcontract.ParameterField(transaction.Name, names[i]))
+		}
+	}
+	err = metadata.ValidateAgainstSchema(chaincodeMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataJson, err := json.MarshalIndent(chaincodeMetadata, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+	metadataJson = append(metadataJson, '\n')
+	err = ioutil.WriteFile(path, metadataJson, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return metadataJson, nil
+}
+
+// writeEmbedded writes the Go file holding metadataJson as the contractMetadata constant
+func writeEmbedded(path string, metadataJson []byte) error {
+	if bytes.ContainsRune(metadataJson, '`') {
+		return fmt.Errorf("the metadata contains a backquote and cannot be a raw string literal")
+	}
+	var out bytes.Buffer
+	out.WriteString("// Code generated by metadatagen from the transaction function signatures. DO NOT EDIT.\n\n")
+	out.WriteString("package smartcontract\n\n")
+	out.WriteString("// contractMetadata contract-metadata/metadata.json, see InstallContractMetadata\n")
+	out.WriteString("const contractMetadata = `" + string(metadataJson) + "`\n")
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, source, 0644)
+}
@@ -3,7 +3,11 @@ module users
 go 1.15
 
 require (
+	github.com/go-openapi/spec v0.19.4
+	github.com/golang/protobuf v1.3.2
 	github.com/hyperledger/fabric-chaincode-go v0.0.0-20200424173110-d7076418f212
 	github.com/hyperledger/fabric-contract-api-go v1.1.1
+	github.com/hyperledger/fabric-protos-go v0.0.0-20200424173316-dd554ba3746e
 	github.com/stretchr/testify v1.5.1
+	golang.org/x/text v0.3.2
 )
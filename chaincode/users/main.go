@@ -10,7 +10,12 @@ import (
 
 func main() {
 	fmt.Printf("main")
-	chaincode, err := contractapi.NewChaincode(new(smartcontract.SmartContract))
+	// without the metadata file GetMetadata publishes no validation rules, the chaincode
+	// still validates its parameters
+	if _, err := smartcontract.InstallContractMetadata(); err != nil {
+		log.Printf("Error install contract metadata: %s", err.Error())
+	}
+	chaincode, err := contractapi.NewChaincode(smartcontract.NewSmartContract())
 
 	if err != nil {
 		log.Printf("Error create chaincode: %s", err.Error())
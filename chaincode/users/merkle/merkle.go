@@ -0,0 +1,246 @@
+// Package merkle builds and verifies the Merkle trees the users chaincode commits
+// transaction hashes to. Trees follow RFC 6962: leaves and interior nodes are hashed with
+// SHA-256 under distinct 0x00 and 0x01 prefixes, and a tree of n leaves splits after the
+// largest power of two below n. It has no Fabric dependency so proofs can be checked
+// offline
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Proof inclusion proof of the leaf at Index in a tree of Size leaves. Siblings are the
+// hex encoded hashes of the audit path, from the leaf up
+type Proof struct {
+	Index    int      `json:"index"`
+	Size     int      `json:"size"`
+	Siblings []string `json:"siblings"`
+}
+
+// LeafHash returns the hash of the leaf holding data
+func LeafHash(data []byte) []byte {
+	sum := sha256.Sum256(append([]byte{0x00}, data...))
+	return sum[:]
+}
+
+// NodeHash returns the hash of the interior node with children left and right
+func NodeHash(left []byte, right []byte) []byte {
+	sum := sha256.Sum256(append(append([]byte{0x01}, left...), right...))
+	return sum[:]
+}
+
+// split returns the size of the left subtree of a tree of n > 1 leaves
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// Root returns the root of the tree over the leaf hashes leaves, the hash of the empty
+// string for no leaves
+func Root(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return NodeHash(Root(leaves[:k]), Root(leaves[k:]))
+}
+
+// Prove returns the inclusion proof of the leaf at index among the leaf hashes leaves
+func Prove(leaves [][]byte, index int) (*Proof, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf %d is out of a tree of %d leaves", index, len(leaves))
+	}
+	return &Proof{Index: index, Size: len(leaves), Siblings: path(leaves, index)}, nil
+}
+
+func path(leaves [][]byte, index int) []string {
+	if len(leaves) <= 1 {
+		return []string{}
+	}
+	k := split(len(leaves))
+	if index < k {
+		return append(path(leaves[:k], index), hex.EncodeToString(Root(leaves[k:])))
+	}
+	return append(path(leaves[k:], index-k), hex.EncodeToString(Root(leaves[:k])))
+}
+
+// NodeReader returns the root of the perfect subtree over the 2^level leaves starting at
+// leaf index<<level, a leaf hash for level 0
+type NodeReader func(level int, index int) ([]byte, error)
+
+// ProveFromNodes returns the inclusion proof of the leaf at index in a tree of size leaves
+// like Prove, reading only the perfect subtree roots the audit path needs through node,
+// such as those returned by Frontier.AppendNodes, instead of every leaf
+func ProveFromNodes(size int, index int, node NodeReader) (*Proof, error) {
+	if index < 0 || index >= size {
+		return nil, fmt.Errorf("leaf %d is out of a tree of %d leaves", index, size)
+	}
+	siblings, err := pathFromNodes(0, size, index, node)
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{Index: index, Size: size, Siblings: siblings}, nil
+}
+
+func pathFromNodes(start int, n int, index int, node NodeReader) ([]string, error) {
+	if n <= 1 {
+		return []string{}, nil
+	}
+	k := split(n)
+	subtreeStart, subtreeSize, siblingStart, siblingSize := start, k, start+k, n-k
+	if index >= k {
+		subtreeStart, subtreeSize, siblingStart, siblingSize = start+k, n-k, start, k
+		index -= k
+	}
+	siblings, err := pathFromNodes(subtreeStart, subtreeSize, index, node)
+	if err != nil {
+		return nil, err
+	}
+	sibling, err := subtreeRoot(siblingStart, siblingSize, node)
+	if err != nil {
+		return nil, err
+	}
+	return append(siblings, hex.EncodeToString(sibling)), nil
+}
+
+// subtreeRoot returns the root over the n leaves from start, which the tree splits at a
+// multiple of the largest power of two below n, so its perfect subtrees are stored nodes
+func subtreeRoot(start int, n int, node NodeReader) ([]byte, error) {
+	if n&(n-1) == 0 {
+		level := 0
+		for 1<<level < n {
+			level++
+		}
+		return node(level, start>>level)
+	}
+	k := split(n)
+	left, err := subtreeRoot(start, k, node)
+	if err != nil {
+		return nil, err
+	}
+	right, err := subtreeRoot(start+k, n-k, node)
+	if err != nil {
+		return nil, err
+	}
+	return NodeHash(left, right), nil
+}
+
+// RootFromProof returns the root implied by the leaf hash leaf and proof, following the
+// verification algorithm of RFC 9162 section 2.1.3.2
+func RootFromProof(leaf []byte, proof *Proof) ([]byte, error) {
+	if proof.Index < 0 || proof.Index >= proof.Size {
+		return nil, fmt.Errorf("leaf %d is out of a tree of %d leaves", proof.Index, proof.Size)
+	}
+	fn, sn := proof.Index, proof.Size-1
+	root := leaf
+	for _, sibling := range proof.Siblings {
+		if sn == 0 {
+			return nil, fmt.Errorf("proof has more siblings than the tree has levels")
+		}
+		hash, err := hex.DecodeString(sibling)
+		if err != nil {
+			return nil, fmt.Errorf("sibling %q is not hex encoded", sibling)
+		}
+		if fn%2 == 1 || fn == sn {
+			root = NodeHash(hash, root)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			root = NodeHash(root, hash)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return nil, fmt.Errorf("proof has fewer siblings than the tree has levels")
+	}
+	return root, nil
+}
+
+// Verify reports whether proof shows the leaf hash leaf is part of the tree with the hex
+// encoded root
+func Verify(leaf []byte, proof *Proof, root string) bool {
+	computed, err := RootFromProof(leaf, proof)
+	if err != nil {
+		return false
+	}
+	expected, err := hex.DecodeString(root)
+	return err == nil && bytes.Equal(computed, expected)
+}
+
+// Frontier grows a tree one leaf at a time keeping only the roots of its perfect
+// subtrees, largest first, so appending and computing the root take logarithmic time. Its
+// Root equals Root over every leaf appended
+type Frontier struct {
+	Size  int      `json:"size"`
+	Nodes []string `json:"nodes"` // hex encoded subtree roots
+}
+
+// Node root of the perfect subtree over the 2^Level leaves starting at leaf Index<<Level
+type Node struct {
+	Level int
+	Index int
+	Hash  []byte
+}
+
+// Append adds the leaf hash leaf to the tree
+func (f *Frontier) Append(leaf []byte) error {
+	_, err := f.AppendNodes(leaf)
+	return err
+}
+
+// AppendNodes adds the leaf hash leaf to the tree and returns the perfect subtrees it
+// completed, the leaf first. Keeping them lets ProveFromNodes prove any leaf
+func (f *Frontier) AppendNodes(leaf []byte) ([]Node, error) {
+	node := leaf
+	nodes := []Node{{Level: 0, Index: f.Size, Hash: leaf}}
+	for size := f.Size; size&1 == 1; size >>= 1 {
+		last := len(f.Nodes) - 1
+		if last < 0 {
+			return nil, fmt.Errorf("frontier has fewer nodes than its size implies")
+		}
+		left, err := hex.DecodeString(f.Nodes[last])
+		if err != nil {
+			return nil, fmt.Errorf("frontier node %q is not hex encoded", f.Nodes[last])
+		}
+		node = NodeHash(left, node)
+		f.Nodes = f.Nodes[:last]
+		nodes = append(nodes, Node{Level: len(nodes), Index: f.Size >> len(nodes), Hash: node})
+	}
+	f.Nodes = append(f.Nodes, hex.EncodeToString(node))
+	f.Size++
+	return nodes, nil
+}
+
+// Root returns the root of the tree, the hash of the empty string when it has no leaves
+func (f *Frontier) Root() ([]byte, error) {
+	if len(f.Nodes) == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:], nil
+	}
+	var root []byte
+	for i := len(f.Nodes) - 1; i >= 0; i-- {
+		node, err := hex.DecodeString(f.Nodes[i])
+		if err != nil {
+			return nil, fmt.Errorf("frontier node %q is not hex encoded", f.Nodes[i])
+		}
+		if root == nil {
+			root = node
+		} else {
+			root = NodeHash(node, root)
+		}
+	}
+	return root, nil
+}
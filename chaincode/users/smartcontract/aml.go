@@ -0,0 +1,265 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const AMLRulePrefix = "AMLRule_"
+const AlertPrefix = "Alert_"
+
+// amlDailyTotalIndex composite key amlDaily~userId~currency~date holding the running
+// total a user transacted in a currency on a given day
+const amlDailyTotalIndex = "amlDaily"
+
+const (
+	AlertRuleThreshold  = "threshold"
+	AlertRuleDailyLimit = "daily_limit"
+
+	AlertStatusOpen     = "open"
+	AlertStatusResolved = "resolved"
+)
+
+// AMLRule anti-money-laundering limits for one currency. An empty limit is not checked
+type AMLRule struct {
+	Currency   string `json:"currency"`
+	Threshold  string `json:"threshold"`   // single transaction amount
+	DailyLimit string `json:"daily_limit"` // total per user per day
+}
+
+// Alert raised when a transaction breaks an AMLRule
+type Alert struct {
+	ID              string `json:"id"`
+	UserId          string `json:"user_id"`
+	TransactionHash string `json:"transaction_hash"` // the transfer ID for a transfer
+	Rule            string `json:"rule"`
+	Currency        string `json:"currency"`
+	Amount          string `json:"amount"`
+	Limit           string `json:"limit"`
+	Status          string `json:"status"`
+	CreatedAt       string `json:"created_at"`
+	Detail          string `json:"detail,omitempty" metadata:",optional"`
+	Note            string `json:"note,omitempty" metadata:",optional"`
+	ResolvedBy      string `json:"resolved_by,omitempty" metadata:",optional"`
+	ResolvedAt      string `json:"resolved_at,omitempty" metadata:",optional"`
+}
+
+// SetAMLRule creates or replaces the limits for currency. Admin only
+func (s *SmartContract) SetAMLRule(ctx TransactionContextInterface, currency string, threshold string, dailyLimit string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	for _, limit := range []string{threshold, dailyLimit} {
+		if limit == "" {
+			continue
+		}
+		value, err := parseAmount(limit)
+		if err != nil {
+			return err
+		}
+		if value.Sign() < 0 {
+			return fmt.Errorf("limit %s must not be negative", limit)
+		}
+	}
+
+	rule := AMLRule{
+		Currency:   currency,
+		Threshold:  threshold,
+		DailyLimit: dailyLimit,
+	}
+	return ctx.PutStateJSON(AMLRulePrefix+currency, rule)
+}
+
+// GetAMLRule returns the limits for currency
+func (s *SmartContract) GetAMLRule(ctx TransactionContextInterface, currency string) (*AMLRule, error) {
+	var rule AMLRule
+	exists, err := ctx.GetStateJSON(AMLRulePrefix+currency, &rule)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("no AML rule for currency %s", currency)
+	}
+	return &rule, nil
+}
+
+// ListAlerts returns the alerts with the given status, or every alert when status is
+// empty. Admin only
+func (s *SmartContract) ListAlerts(ctx TransactionContextInterface, status string) ([]*Alert, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByRange(AlertPrefix, prefixRangeEnd(AlertPrefix))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	alerts := []*Alert{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var alert Alert
+		err = json.Unmarshal(queryResponse.Value, &alert)
+		if err != nil {
+			return nil, err
+		}
+		if status == "" || alert.Status == status {
+			alerts = append(alerts, &alert)
+		}
+	}
+
+	return alerts, nil
+}
+
+// ResolveAlert closes an open alert with a note from the reviewer. Admin only
+func (s *SmartContract) ResolveAlert(ctx TransactionContextInterface, id string, note string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	var alert Alert
+	exists, err := ctx.GetStateJSON(AlertPrefix+id, &alert)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the alert %s does not exist", id)
+	}
+	if alert.Status != AlertStatusOpen {
+		return fmt.Errorf("the alert %s is already %s", id, alert.Status)
+	}
+
+	resolvedBy, err := ctx.GetCallerID()
+	if err != nil {
+		return err
+	}
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	alert.Status = AlertStatusResolved
+	alert.Note = note
+	alert.ResolvedBy = resolvedBy
+	alert.ResolvedAt = now.Format(time.RFC3339)
+
+	return ctx.PutStateJSON(AlertPrefix+id, alert)
+}
+
+// screenTransaction checks transaction against the AML rule of its currency, updates the
+// user's daily total and stores an Alert for every limit exceeded, which it returns for
+// the caller to emit. The transaction itself is never rejected
+func (s *SmartContract) screenTransaction(ctx TransactionContextInterface, userId string, transaction Transaction) ([]*Alert, error) {
+	// daily totals follow the recorded date, a client supplied business date could spread
+	// transactions over several days to stay under the limit
+	day := transaction.Date
+	if len(transaction.CreatedAt) >= len("2006-01-02") {
+		day = transaction.CreatedAt[:len("2006-01-02")]
+	}
+	dailyKey, err := ctx.GetStub().CreateCompositeKey(amlDailyTotalIndex, []string{userId, transaction.Currency, day})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", amlDailyTotalIndex, err)
+	}
+	dailyTotalBytes, err := ctx.GetStub().GetState(dailyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	dailyTotal := "0"
+	if dailyTotalBytes != nil {
+		dailyTotal = string(dailyTotalBytes)
+	}
+	dailyTotal, err = addAmounts(dailyTotal, transaction.Amount)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(dailyKey, []byte(dailyTotal))
+	if err != nil {
+		return nil, err
+	}
+
+	var rule AMLRule
+	exists, err := ctx.GetStateJSON(AMLRulePrefix+transaction.Currency, &rule)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var alerts []*Alert
+	checks := []struct{ rule, amount, limit string }{
+		{AlertRuleThreshold, transaction.Amount, rule.Threshold},
+		{AlertRuleDailyLimit, dailyTotal, rule.DailyLimit},
+	}
+	for _, check := range checks {
+		if check.limit == "" {
+			continue
+		}
+		exceeded, err := amountExceeds(check.amount, check.limit)
+		if err != nil {
+			return nil, err
+		}
+		if exceeded {
+			alerts = append(alerts, &Alert{
+				ID:              transaction.Hash + "_" + check.rule,
+				UserId:          userId,
+				TransactionHash: transaction.Hash,
+				Rule:            check.rule,
+				Currency:        transaction.Currency,
+				Amount:          check.amount,
+				Limit:           check.limit,
+				Status:          AlertStatusOpen,
+			})
+		}
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return nil, err
+	}
+	for _, alert := range alerts {
+		alert.CreatedAt = now.Format(time.RFC3339)
+		err = ctx.PutStateJSON(AlertPrefix+alert.ID, alert)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return alerts, nil
+}
+
+// emitAMLAlerts emits alerts, if any, in an AMLAlert event. Fabric keeps only the last
+// event set by a transaction, so every alert it raises must be emitted in one call
+func emitAMLAlerts(ctx TransactionContextInterface, alerts []*Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	alertsJson, err := json.Marshal(alerts)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("AMLAlert", alertsJson)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+	return nil
+}
+
+// amountExceeds reports whether amount is strictly greater than limit
+func amountExceeds(amount string, limit string) (bool, error) {
+	x, err := parseAmount(amount)
+	if err != nil {
+		return false, err
+	}
+	y, err := parseAmount(limit)
+	if err != nil {
+		return false, err
+	}
+	return x.Cmp(y) > 0, nil
+}
@@ -0,0 +1,45 @@
+package smartcontract
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// amountPattern accepts at most 8 decimals, the precision formatAmount keeps, so that the
+// canonical payload of a transaction always renders its recorded amount exactly
+var amountPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]{1,8})?$`)
+
+// parseAmount parses a decimal amount such as "200" or "12.50" into an exact rational,
+// so totals never suffer from floating point rounding
+func parseAmount(amount string) (*big.Rat, error) {
+	if !amountPattern.MatchString(amount) {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	return value, nil
+}
+
+// formatAmount renders value as the shortest decimal string with at most 8 decimals,
+// e.g. "12.5" for 12.50 and "200" for 200.00
+func formatAmount(value *big.Rat) string {
+	amount := strings.TrimRight(value.FloatString(8), "0")
+	return strings.TrimSuffix(amount, ".")
+}
+
+// addAmounts returns the sum of two decimal amounts as a normalized string
+func addAmounts(a string, b string) (string, error) {
+	x, err := parseAmount(a)
+	if err != nil {
+		return "", err
+	}
+	y, err := parseAmount(b)
+	if err != nil {
+		return "", err
+	}
+	return formatAmount(new(big.Rat).Add(x, y)), nil
+}
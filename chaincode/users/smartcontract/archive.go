@@ -0,0 +1,343 @@
+package smartcontract
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"users/merkle"
+	"users/verifier"
+)
+
+const ArchivePrefix = "Archive_"
+
+// ArchiveKeyPrefix starts the keys of the registered archive signing keys, by version
+const ArchiveKeyPrefix = "ArchiveKey_"
+
+// archiveKeyVersionKey holds the version of the current archive signing key
+const archiveKeyVersionKey = "ArchiveKeyVersion"
+
+// ArchiveKey public key, PEM PKIX Ed25519, verifying the ArchiveSummary signatures made
+// with one version of the archive signing key
+type ArchiveKey struct {
+	Version      int    `json:"version"`
+	PublicKey    string `json:"public_key"`
+	RegisteredAt string `json:"registered_at"`
+}
+
+// ArchiveSummary replaces the transactions of a user dated before BeforeDate once they
+// are archived. MerkleRoot commits to their hashes in recording order, see
+// verifier.TransactionLeafHash, and Signature is the base64 Ed25519 signature of
+// ArchiveSummaryPayload by the key supplied under SigningKeyTransientKey, which must be
+// the registered ArchiveKey of KeyVersion
+type ArchiveSummary struct {
+	ID         string            `json:"id"`
+	UserId     string            `json:"user_id"`
+	BeforeDate string            `json:"before_date"`
+	Count      int               `json:"count"`
+	Totals     map[string]string `json:"totals"` // currency -> sum of the archived transactions
+	MerkleRoot string            `json:"merkle_root"`
+	ArchivedBy string            `json:"archived_by"`
+	ArchivedAt string            `json:"archived_at"`
+	KeyVersion int               `json:"key_version"` // version of the ArchiveKey verifying Signature
+	Signature  string            `json:"signature"`
+}
+
+// ArchivedTransaction an archived transaction with the proof of its inclusion in the
+// MerkleRoot of its ArchiveSummary
+type ArchivedTransaction struct {
+	Transaction
+	Proof *merkle.Proof `json:"proof"`
+}
+
+// ArchiveResult returned by ArchiveTransactions. The archived transactions are no longer
+// on the ledger, the caller keeps them with their proofs
+type ArchiveResult struct {
+	Summary      *ArchiveSummary        `json:"summary"`
+	Transactions []*ArchivedTransaction `json:"transactions"`
+}
+
+// ArchiveSummaryPayload returns the bytes the Signature of summary covers
+func ArchiveSummaryPayload(summary *ArchiveSummary) ([]byte, error) {
+	return json.Marshal(struct {
+		ID         string            `json:"id"`
+		UserId     string            `json:"user_id"`
+		BeforeDate string            `json:"before_date"`
+		Count      int               `json:"count"`
+		Totals     map[string]string `json:"totals"`
+		MerkleRoot string            `json:"merkle_root"`
+		ArchivedAt string            `json:"archived_at"`
+		KeyVersion int               `json:"key_version"`
+	}{summary.ID, summary.UserId, summary.BeforeDate, summary.Count, summary.Totals, summary.MerkleRoot, summary.ArchivedAt, summary.KeyVersion})
+}
+
+// RegisterArchiveKey registers publicKey, a PEM PKIX Ed25519 key, as the next version of
+// the archive signing key. Archives are signed with the latest version from then on, the
+// earlier versions still verify the archives they signed. Admin only
+func (s *SmartContract) RegisterArchiveKey(ctx TransactionContextInterface, publicKey string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = parseVerificationKey([]byte(publicKey))
+	if err != nil {
+		return err
+	}
+	version, err := currentArchiveKeyVersion(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+
+	key := ArchiveKey{
+		Version:      version + 1,
+		PublicKey:    publicKey,
+		RegisteredAt: now,
+	}
+	err = ctx.PutStateJSON(ArchiveKeyPrefix+strconv.Itoa(key.Version), key)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(archiveKeyVersionKey, []byte(strconv.Itoa(key.Version)))
+}
+
+// GetArchiveKey returns version of the archive signing key
+func (s *SmartContract) GetArchiveKey(ctx TransactionContextInterface, version int) (*ArchiveKey, error) {
+	var key ArchiveKey
+	exists, err := ctx.GetStateJSON(ArchiveKeyPrefix+strconv.Itoa(version), &key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the archive key version %d is not registered", version)
+	}
+	return &key, nil
+}
+
+// currentArchiveKeyVersion returns the latest registered archive key version, 0 when none
+func currentArchiveKeyVersion(ctx TransactionContextInterface) (int, error) {
+	versionBytes, err := ctx.GetStub().GetState(archiveKeyVersionKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if versionBytes == nil {
+		return 0, nil
+	}
+	return strconv.Atoi(string(versionBytes))
+}
+
+// signArchiveSummary signs summary with the Ed25519 key in the transient map, which must be
+// the current registered ArchiveKey
+func (s *SmartContract) signArchiveSummary(ctx TransactionContextInterface, summary *ArchiveSummary) error {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to read transient map: %v", err)
+	}
+	keyPem := transientMap[SigningKeyTransientKey]
+	if keyPem == nil {
+		return fmt.Errorf("archiving requires a signing key under %s in the transient map", SigningKeyTransientKey)
+	}
+	signingKey, err := parseSigningKey(keyPem)
+	if err != nil {
+		return err
+	}
+	version, err := currentArchiveKeyVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		return fmt.Errorf("no archive signing key is registered, see RegisterArchiveKey")
+	}
+	registered, err := s.GetArchiveKey(ctx, version)
+	if err != nil {
+		return err
+	}
+	publicKey, err := parseVerificationKey([]byte(registered.PublicKey))
+	if err != nil {
+		return err
+	}
+	if !publicKey.Equal(signingKey.Public()) {
+		return fmt.Errorf("the signing key is not the registered archive key version %d", version)
+	}
+	summary.KeyVersion = version
+	payload, err := ArchiveSummaryPayload(summary)
+	if err != nil {
+		return err
+	}
+	summary.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(signingKey, payload))
+	return nil
+}
+
+// ArchiveTransactions rolls the transactions of userId dated before beforeDate into a
+// signed ArchiveSummary. Their details and index entries are deleted, their hashes stay
+// mapped to the archive so they can neither be recorded again nor go unexplained.
+// Transactions under an unresolved dispute cannot be archived. Admin only, the signing
+// key is supplied under SigningKeyTransientKey
+func (s *SmartContract) ArchiveTransactions(ctx TransactionContextInterface, userId string, beforeDate string) (*ArchiveResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse("2006-01-02", beforeDate); err != nil {
+		return nil, fmt.Errorf("invalid date %q", beforeDate)
+	}
+	user, err := s.readUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	archivedBy, err := ctx.GetCallerID()
+	if err != nil {
+		return nil, err
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := ArchiveSummary{
+		ID:         userId + "_" + ctx.GetStub().GetTxID(),
+		UserId:     userId,
+		BeforeDate: beforeDate,
+		Totals:     map[string]string{},
+		ArchivedBy: archivedBy,
+		ArchivedAt: now,
+	}
+	var archived, kept []Transaction
+	var leaves [][]byte
+	for _, transaction := range user.Transactions {
+		if transaction.Date >= beforeDate {
+			kept = append(kept, transaction)
+			continue
+		}
+		var dispute Dispute
+		disputed, err := ctx.GetStateJSON(DisputePrefix+transaction.Hash, &dispute)
+		if err != nil {
+			return nil, err
+		}
+		if disputed && dispute.Status != DisputeResolved {
+			return nil, fmt.Errorf("the transaction %s is under dispute and cannot be archived", transaction.Hash)
+		}
+		err = addToTotals(summary.Totals, transaction.Currency, transaction.Amount)
+		if err != nil {
+			return nil, err
+		}
+		archived = append(archived, transaction)
+		leaves = append(leaves, verifier.TransactionLeafHash(transaction.Hash))
+	}
+	if len(archived) == 0 {
+		return nil, fmt.Errorf("the user %s has no transactions dated before %s", userId, beforeDate)
+	}
+	summary.Count = len(archived)
+	summary.MerkleRoot = hex.EncodeToString(merkle.Root(leaves))
+	err = s.signArchiveSummary(ctx, &summary)
+	if err != nil {
+		return nil, err
+	}
+
+	result := ArchiveResult{Summary: &summary}
+	for i, transaction := range archived {
+		err := unindexTransaction(ctx, userId, transaction)
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.PutStateJSON(transaction.Hash, TransactionHashMapUserId{UserId: userId, ArchiveId: summary.ID})
+		if err != nil {
+			return nil, err
+		}
+		proof, err := merkle.Prove(leaves, i)
+		if err != nil {
+			return nil, err
+		}
+		result.Transactions = append(result.Transactions, &ArchivedTransaction{Transaction: transaction, Proof: proof})
+	}
+
+	user.Transactions = kept
+	user.Archives = append(user.Archives, summary.ID)
+	user.UpdatedAt = now
+	err = ctx.PutStateJSON(userId, user)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.PutStateJSON(ArchivePrefix+summary.ID, summary)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetArchiveSummary returns the archive id. Subject to the same consent as the user's data
+func (s *SmartContract) GetArchiveSummary(ctx TransactionContextInterface, id string) (*ArchiveSummary, error) {
+	var summary ArchiveSummary
+	exists, err := ctx.GetStateJSON(ArchivePrefix+id, &summary)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the archive %s does not exist", id)
+	}
+	err = s.requireConsent(ctx, summary.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// VerifyArchivedTransaction reports whether proof, a JSON encoded merkle.Proof as returned
+// by ArchiveTransactions, shows the archived transaction hash is part of its archive. The
+// signature of the archive is checked too, against the registered ArchiveKey it names
+func (s *SmartContract) VerifyArchivedTransaction(ctx TransactionContextInterface, hash string, proof string) (bool, error) {
+	hash = normalizeHash(hash)
+	var transactionHashMapUserId TransactionHashMapUserId
+	exists, err := ctx.GetStateJSON(hash, &transactionHashMapUserId)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, fmt.Errorf("the transaction %s does not exist", hash)
+	}
+	if transactionHashMapUserId.ArchiveId == "" {
+		return false, fmt.Errorf("the transaction %s is not archived", hash)
+	}
+	var summary ArchiveSummary
+	exists, err = ctx.GetStateJSON(ArchivePrefix+transactionHashMapUserId.ArchiveId, &summary)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, fmt.Errorf("the archive %s does not exist", transactionHashMapUserId.ArchiveId)
+	}
+
+	key, err := s.GetArchiveKey(ctx, summary.KeyVersion)
+	if err != nil {
+		return false, err
+	}
+	publicKey, err := parseVerificationKey([]byte(key.PublicKey))
+	if err != nil {
+		return false, err
+	}
+	payload, err := ArchiveSummaryPayload(&summary)
+	if err != nil {
+		return false, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(summary.Signature)
+	if err != nil || !ed25519.Verify(publicKey, payload, signature) {
+		return false, fmt.Errorf("the signature of archive %s does not verify", summary.ID)
+	}
+
+	var inclusion merkle.Proof
+	err = json.Unmarshal([]byte(proof), &inclusion)
+	if err != nil {
+		return false, fmt.Errorf("proof is not a valid JSON Merkle proof: %v", err)
+	}
+	if inclusion.Size != summary.Count {
+		return false, nil
+	}
+	return merkle.Verify(verifier.TransactionLeafHash(hash), &inclusion, summary.MerkleRoot), nil
+}
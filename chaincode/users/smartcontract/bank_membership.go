@@ -0,0 +1,97 @@
+package smartcontract
+
+import (
+	"fmt"
+)
+
+// bankMSPPrefix starts the reverse lookup keys from an MSP ID to the bank it belongs to
+const bankMSPPrefix = "BankMSP_"
+
+// SetBankMSPID binds bankId to the Fabric organization mspId. From then on only clients of
+// that MSP may record transactions at the bank, and they may list them with
+// ListMyBankTransactions. An organization belongs to one bank at most and cannot be one
+// added with AddUserMSP. Admin only
+func (s *SmartContract) SetBankMSPID(ctx TransactionContextInterface, bankId string, mspId string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if mspId == "" {
+		return fmt.Errorf("MSP ID must not be empty")
+	}
+	bank, err := s.GetBankByID(ctx, bankId)
+	if err != nil {
+		return err
+	}
+	boundBank, err := ctx.GetStub().GetState(bankMSPPrefix + mspId)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if boundBank != nil && string(boundBank) != bankId {
+		return fmt.Errorf("the MSP %s already belongs to bank %s", mspId, boundBank)
+	}
+	userMSP, err := isUserMSP(ctx, mspId)
+	if err != nil {
+		return err
+	}
+	if userMSP {
+		return fmt.Errorf("the MSP %s is trusted to issue user identities", mspId)
+	}
+	if bank.MSPID != "" && bank.MSPID != mspId {
+		err = ctx.GetStub().DelState(bankMSPPrefix + bank.MSPID)
+		if err != nil {
+			return err
+		}
+	}
+
+	bank.MSPID = mspId
+	bank.UpdatedAt, err = recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	err = ctx.PutStateJSON(BankPrefix+bankId, bank)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(bankMSPPrefix+mspId, []byte(bankId))
+}
+
+// requireBankMSP rejects callers outside the MSP of bank. Banks not bound to an MSP yet
+// have no organization of their own, only the admin organization acts for them
+func requireBankMSP(ctx TransactionContextInterface, bank *Bank) error {
+	mspID, err := ctx.GetCallerMSPID()
+	if err != nil {
+		return err
+	}
+	if bank.MSPID == "" {
+		if mspID != AdminMSPID {
+			return fmt.Errorf("client from %s is not authorized to act for bank %s, which is not bound to an MSP", mspID, bank.ID)
+		}
+		return nil
+	}
+	if mspID != bank.MSPID {
+		return fmt.Errorf("client from %s is not authorized to act for bank %s", mspID, bank.ID)
+	}
+	return nil
+}
+
+// ListMyBankTransactions is ListTransactionsByBank for the bank of the caller's MSP. A
+// bank sees every transaction recorded at it, without the users' consent
+func (s *SmartContract) ListMyBankTransactions(ctx TransactionContextInterface, startDate string, endDate string, dateField string, pageSize int, bookmark string) (*TransactionPage, error) {
+	mspID, err := ctx.GetCallerMSPID()
+	if err != nil {
+		return nil, err
+	}
+	bankId, err := ctx.GetStub().GetState(bankMSPPrefix + mspID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if bankId == nil {
+		return nil, fmt.Errorf("the MSP %s does not belong to a bank", mspID)
+	}
+	_, index, err := dateIndexes(dateField)
+	if err != nil {
+		return nil, err
+	}
+	return s.listTransactionIndex(ctx, index, []string{string(bankId)}, startDate, endDate, pageSize, bookmark, false)
+}
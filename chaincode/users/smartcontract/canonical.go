@@ -0,0 +1,103 @@
+package smartcontract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// canonicalTransaction is the payload a transaction hash is computed over. Fields are
+// declared in alphabetical order so the JSON encoding is stable
+type canonicalTransaction struct {
+	Amount   string `json:"amount"`
+	BankId   string `json:"bank_id"`
+	Currency string `json:"currency"`
+	Date     string `json:"date"`
+	// Reference tells apart identical payments of the same day, left out when empty so
+	// hashes of transactions recorded without one stay valid
+	Reference string `json:"reference,omitempty"`
+	// a standing order records the same payment on every run, the order and run number
+	// tell the runs apart
+	StandingOrderID  string `json:"standing_order_id,omitempty"`
+	StandingOrderRun int    `json:"standing_order_run,omitempty"`
+	UserId           string `json:"user_id"`
+}
+
+// CanonicalTransaction returns the canonical serialization of transaction recorded for
+// userId: a JSON object with sorted keys and the amount normalized by formatAmount, so
+// "200.00" and "200" serialize alike. The client Reference and the standing order run are
+// part of it, the hash and token transfer are not
+func CanonicalTransaction(userId string, transaction Transaction) ([]byte, error) {
+	amount, err := parseAmount(transaction.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(canonicalTransaction{
+		Amount:           formatAmount(amount),
+		BankId:           transaction.BankId,
+		Currency:         transaction.Currency,
+		Date:             transaction.Date,
+		Reference:        transaction.Reference,
+		StandingOrderID:  transaction.StandingOrderID,
+		StandingOrderRun: transaction.StandingOrderRun,
+		UserId:           userId,
+	})
+}
+
+// TransactionHash returns the hex encoded SHA-256 of CanonicalTransaction, the value
+// CreateTransaction expects as hash
+func TransactionHash(userId string, transaction Transaction) (string, error) {
+	payload, err := CanonicalTransaction(userId, transaction)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeHash returns hash as lower case hex without 0x prefix, the spelling every
+// transaction is recorded and looked up under
+func normalizeHash(hash string) string {
+	return strings.TrimPrefix(strings.ToLower(hash), "0x")
+}
+
+// checkTransactionHash rejects a transaction whose hash is not the SHA-256 of its content.
+// An optional 0x prefix and upper case digits are accepted
+func checkTransactionHash(userId string, transaction Transaction) error {
+	computed, err := TransactionHash(userId, transaction)
+	if err != nil {
+		return err
+	}
+	if normalizeHash(transaction.Hash) != computed {
+		return fmt.Errorf("hash %s does not match the transaction content, expected %s", transaction.Hash, computed)
+	}
+	return nil
+}
+
+// findTransaction returns the user owning the transaction with hash and the position of
+// the transaction in user.Transactions
+func (s *SmartContract) findTransaction(ctx TransactionContextInterface, hash string) (*User, int, error) {
+	hash = normalizeHash(hash)
+	user, err := s.readUserByTransactionHash(ctx, hash)
+	if err != nil {
+		return nil, -1, err
+	}
+	for i, transaction := range user.Transactions {
+		if transaction.Hash == hash {
+			return user, i, nil
+		}
+	}
+	return nil, -1, fmt.Errorf("the transaction %s is not recorded for user %s", hash, user.ID)
+}
+
+// VerifyTransaction recomputes the hash of the stored transaction and reports whether it
+// still matches the content
+func (s *SmartContract) VerifyTransaction(ctx TransactionContextInterface, hash string) (bool, error) {
+	user, i, err := s.findTransaction(ctx, hash)
+	if err != nil {
+		return false, err
+	}
+	return checkTransactionHash(user.ID, user.Transactions[i]) == nil, nil
+}
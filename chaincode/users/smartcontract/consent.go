@@ -0,0 +1,212 @@
+package smartcontract
+
+import (
+	"fmt"
+	"time"
+)
+
+// consentIndex composite key consent~userId~mspId
+const consentIndex = "consent"
+
+const (
+	ConsentGranted = "granted"
+	ConsentRevoked = "revoked"
+)
+
+// Consent permission given by a user to the bank behind MSPID to read its profile and
+// transactions, up to and including ExpiresOn
+type Consent struct {
+	UserId    string `json:"user_id"`
+	MSPID     string `json:"msp_id" minLength:"1" maxLength:"64"`
+	ExpiresOn string `json:"expires_on" format:"date"`
+	Status    string `json:"status"`
+	GrantedAt string `json:"granted_at"`
+	RevokedAt string `json:"revoked_at,omitempty" metadata:",optional"`
+}
+
+func consentKey(ctx TransactionContextInterface, userId string, mspId string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(consentIndex, []string{userId, mspId})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", consentIndex, err)
+	}
+	return key, nil
+}
+
+// GrantConsent lets the bank behind mspId read the data of userId until expiresOn,
+// replacing any earlier consent. Only the user itself may grant it
+func (s *SmartContract) GrantConsent(ctx TransactionContextInterface, userId string, mspId string, expiresOn string) error {
+	err := requireUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	_, err = s.readUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	if expiresOn < now.Format("2006-01-02") {
+		return fmt.Errorf("consent expiry %s is in the past", expiresOn)
+	}
+	key, err := consentKey(ctx, userId, mspId)
+	if err != nil {
+		return err
+	}
+	consent := Consent{
+		UserId:    userId,
+		MSPID:     mspId,
+		ExpiresOn: expiresOn,
+		Status:    ConsentGranted,
+		GrantedAt: now.Format(time.RFC3339),
+	}
+	return ctx.PutStateJSON(key, consent)
+}
+
+// RevokeConsent withdraws the consent userId gave to the bank behind mspId. Only the user
+// itself may revoke it
+func (s *SmartContract) RevokeConsent(ctx TransactionContextInterface, userId string, mspId string) error {
+	err := requireUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	key, err := consentKey(ctx, userId, mspId)
+	if err != nil {
+		return err
+	}
+	var consent Consent
+	exists, err := ctx.GetStateJSON(key, &consent)
+	if err != nil {
+		return err
+	}
+	if !exists || consent.Status != ConsentGranted {
+		return fmt.Errorf("the user %s has not granted consent to %s", userId, mspId)
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	consent.Status = ConsentRevoked
+	consent.RevokedAt = now
+	return ctx.PutStateJSON(key, consent)
+}
+
+// ListUserConsents returns every consent userId granted, revoked ones included, ordered by
+// MSP ID. Subject to the same consent as the user's data
+func (s *SmartContract) ListUserConsents(ctx TransactionContextInterface, userId string) ([]*Consent, error) {
+	err := s.requireConsent(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.userConsents(ctx, userId)
+}
+
+// userConsents returns the consents userId granted without checking the caller's consent
+func (s *SmartContract) userConsents(ctx TransactionContextInterface, userId string) ([]*Consent, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(consentIndex, []string{userId})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	consents := []*Consent{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var consent Consent
+		exists, err := ctx.GetStateJSON(queryResponse.Key, &consent)
+		if err != nil || !exists {
+			return nil, err
+		}
+		consents = append(consents, &consent)
+	}
+	return consents, nil
+}
+
+// deleteUserConsents deletes every consent userId granted, so that a user created later
+// under the same ID does not inherit them
+func deleteUserConsents(ctx TransactionContextInterface, userId string) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(consentIndex, []string{userId})
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	var keys []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+		keys = append(keys, queryResponse.Key)
+	}
+	for _, key := range keys {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasConsent reports whether the caller may read the data of userId: the user itself, the
+// admin organization, or a bank whose MSP holds an unexpired consent of the user
+func (s *SmartContract) hasConsent(ctx TransactionContextInterface, userId string) (bool, error) {
+	mspId, err := ctx.GetCallerMSPID()
+	if err != nil {
+		return false, err
+	}
+	if mspId == AdminMSPID {
+		return true, nil
+	}
+	callerUserId, found, err := callerUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+	if found && callerUserId == userId {
+		return true, nil
+	}
+
+	key, err := consentKey(ctx, userId, mspId)
+	if err != nil {
+		return false, err
+	}
+	var consent Consent
+	exists, err := ctx.GetStateJSON(key, &consent)
+	if err != nil || !exists {
+		return false, err
+	}
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return false, err
+	}
+	return consent.Status == ConsentGranted && now.Format("2006-01-02") <= consent.ExpiresOn, nil
+}
+
+// requireConsent rejects callers without consent to read the data of userId
+func (s *SmartContract) requireConsent(ctx TransactionContextInterface, userId string) error {
+	allowed, err := s.hasConsent(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("no consent of user %s for this client", userId)
+	}
+	return nil
+}
+
+// ListUserTransactions returns the transactions recorded for userId
+func (s *SmartContract) ListUserTransactions(ctx TransactionContextInterface, userId string) ([]Transaction, error) {
+	user, err := s.GetUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	transactions := user.Transactions
+	if transactions == nil {
+		transactions = []Transaction{}
+	}
+	return transactions, nil
+}
@@ -0,0 +1,135 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TransactionContextInterface is the transaction context taken by every function of
+// the users chaincode. On top of the stub and client identity it exposes helpers for
+// the caller, the transaction clock and JSON encoded world state entries
+type TransactionContextInterface interface {
+	contractapi.TransactionContextInterface
+	GetCallerID() (string, error)
+	GetCallerMSPID() (string, error)
+	GetCallerAttribute(name string) (string, bool, error)
+	GetTxTime() (time.Time, error)
+	GetStateJSON(key string, value interface{}) (bool, error)
+	PutStateJSON(key string, value interface{}) error
+	GetTransientJSON(key string, value interface{}) (bool, error)
+}
+
+// TransactionContext implementation of TransactionContextInterface, set on the contract
+// through the TransactionContextHandler field
+type TransactionContext struct {
+	contractapi.TransactionContext
+}
+
+// clientIdentity returns the identity of the submitting client. contractapi stores a nil
+// *cid.ClientID when the creator could not be parsed, so that case is reported as an error
+// instead of panicking on first use
+func (ctx *TransactionContext) clientIdentity() (cid.ClientIdentity, error) {
+	ci := ctx.GetClientIdentity()
+	if id, ok := ci.(*cid.ClientID); ci == nil || (ok && id == nil) {
+		return nil, fmt.Errorf("client identity is not available")
+	}
+	return ci, nil
+}
+
+// GetCallerID returns the ID of the submitting client identity
+func (ctx *TransactionContext) GetCallerID() (string, error) {
+	ci, err := ctx.clientIdentity()
+	if err != nil {
+		return "", err
+	}
+	id, err := ci.GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+	return id, nil
+}
+
+// GetCallerMSPID returns the MSP ID of the submitting client identity
+func (ctx *TransactionContext) GetCallerMSPID() (string, error) {
+	ci, err := ctx.clientIdentity()
+	if err != nil {
+		return "", err
+	}
+	mspID, err := ci.GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	return mspID, nil
+}
+
+// GetCallerAttribute returns the value of an attribute of the submitting client identity
+// and whether the attribute was found
+func (ctx *TransactionContext) GetCallerAttribute(name string) (string, bool, error) {
+	ci, err := ctx.clientIdentity()
+	if err != nil {
+		return "", false, err
+	}
+	value, found, err := ci.GetAttributeValue(name)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get attribute %s: %v", name, err)
+	}
+	return value, found, nil
+}
+
+// GetTxTime returns the timestamp of the transaction proposal in UTC. Every endorsing peer
+// sees the same value, so it is safe to use where time.Now() is not
+func (ctx *TransactionContext) GetTxTime() (time.Time, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	return time.Unix(timestamp.Seconds, int64(timestamp.Nanos)).UTC(), nil
+}
+
+// GetStateJSON reads key from the world state and unmarshals it into value. It reports
+// false without touching value when the key does not exist
+func (ctx *TransactionContext) GetStateJSON(key string, value interface{}) (bool, error) {
+	valueJson, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if valueJson == nil {
+		return false, nil
+	}
+	err = json.Unmarshal(valueJson, value)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PutStateJSON marshals value and writes it to the world state under key
+func (ctx *TransactionContext) PutStateJSON(key string, value interface{}) error {
+	valueJson, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, valueJson)
+}
+
+// GetTransientJSON unmarshals the entry key of the proposal's transient map into value.
+// It reports false without touching value when the entry was not supplied
+func (ctx *TransactionContext) GetTransientJSON(key string, value interface{}) (bool, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return false, fmt.Errorf("failed to read transient map: %v", err)
+	}
+	valueJson, ok := transientMap[key]
+	if !ok {
+		return false, nil
+	}
+	err = json.Unmarshal(valueJson, value)
+	if err != nil {
+		return false, fmt.Errorf("failed to unmarshal transient %s: %v", key, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,32 @@
+package smartcontract
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+)
+
+// InstallContractMetadata writes the generated contract metadata to
+// contract-metadata/metadata.json next to the running executable, where contractapi reads
+// it from in NewChaincode. The peer builds the chaincode binary alone, without the files
+// of its package, so the metadata is compiled in and written out at startup. Returns the
+// path written
+func InstallContractMetadata() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(filepath.Dir(executable), metadata.MetadataFolder, metadata.MetadataFile)
+	installed, err := ioutil.ReadFile(path)
+	if err == nil && bytes.Equal(installed, []byte(contractMetadata)) {
+		return path, nil
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return "", err
+	}
+	return path, ioutil.WriteFile(path, []byte(contractMetadata), 0644)
+}
@@ -0,0 +1,178 @@
+package smartcontract
+
+import (
+	"fmt"
+)
+
+const DisputePrefix = "Dispute_"
+
+const (
+	DisputeOpen      = "open"
+	DisputeResponded = "responded"
+	DisputeResolved  = "resolved"
+
+	DisputeUpheld   = "upheld"
+	DisputeRejected = "rejected"
+)
+
+// Dispute raised by a user against one of its transactions, keyed by the transaction hash.
+// It moves from open to responded by the bank, then to resolved by the bank
+type Dispute struct {
+	TransactionHash string          `json:"transaction_hash"`
+	UserId          string          `json:"user_id"`
+	BankId          string          `json:"bank_id"`
+	Reason          string          `json:"reason" minLength:"1" maxLength:"500"`
+	Status          string          `json:"status"`
+	Outcome         string          `json:"outcome,omitempty" pattern:"^(upheld|rejected)$" metadata:",optional"`
+	Timeline        []*DisputeEvent `json:"timeline"`
+}
+
+// DisputeEvent one step of a Dispute
+type DisputeEvent struct {
+	Status string `json:"status"`
+	Actor  string `json:"actor"`
+	Note   string `json:"note,omitempty" maxLength:"500" metadata:",optional"`
+	TxID   string `json:"tx_id"`
+	At     string `json:"at"`
+}
+
+// appendDisputeEvent moves dispute to status, recording who did it and when
+func appendDisputeEvent(ctx TransactionContextInterface, dispute *Dispute, status string, note string) error {
+	actor, err := ctx.GetCallerID()
+	if err != nil {
+		return err
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	dispute.Status = status
+	dispute.Timeline = append(dispute.Timeline, &DisputeEvent{
+		Status: status,
+		Actor:  actor,
+		Note:   note,
+		TxID:   ctx.GetStub().GetTxID(),
+		At:     now,
+	})
+	return nil
+}
+
+// OpenDispute disputes the transaction hash. Only the user the transaction is recorded for
+// may open it, a transaction is disputed at most once
+func (s *SmartContract) OpenDispute(ctx TransactionContextInterface, hash string, reason string) error {
+	hash = normalizeHash(hash)
+	user, i, err := s.findTransaction(ctx, hash)
+	if err != nil {
+		return err
+	}
+	err = requireUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	exists, err := ctx.GetStateJSON(DisputePrefix+hash, &Dispute{})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("the transaction %s is already disputed", hash)
+	}
+
+	dispute := Dispute{
+		TransactionHash: hash,
+		UserId:          user.ID,
+		BankId:          user.Transactions[i].BankId,
+		Reason:          reason,
+	}
+	err = appendDisputeEvent(ctx, &dispute, DisputeOpen, reason)
+	if err != nil {
+		return err
+	}
+	return ctx.PutStateJSON(DisputePrefix+hash, dispute)
+}
+
+// RespondDispute records the answer of the bank of the transaction to an open dispute
+func (s *SmartContract) RespondDispute(ctx TransactionContextInterface, hash string, response string) error {
+	hash = normalizeHash(hash)
+	dispute, err := readDispute(ctx, hash)
+	if err != nil {
+		return err
+	}
+	bank, err := s.GetBankByID(ctx, dispute.BankId)
+	if err != nil {
+		return err
+	}
+	err = requireBankMSP(ctx, bank)
+	if err != nil {
+		return err
+	}
+	if dispute.Status != DisputeOpen {
+		return fmt.Errorf("the dispute of transaction %s is %s, not %s", hash, dispute.Status, DisputeOpen)
+	}
+	err = appendDisputeEvent(ctx, dispute, DisputeResponded, response)
+	if err != nil {
+		return err
+	}
+	return ctx.PutStateJSON(DisputePrefix+hash, dispute)
+}
+
+// ResolveDispute closes a dispute with outcome DisputeUpheld or DisputeRejected. Only the
+// bank of the transaction may resolve it, after or without responding first
+func (s *SmartContract) ResolveDispute(ctx TransactionContextInterface, hash string, outcome string, note string) error {
+	hash = normalizeHash(hash)
+	dispute, err := readDispute(ctx, hash)
+	if err != nil {
+		return err
+	}
+	bank, err := s.GetBankByID(ctx, dispute.BankId)
+	if err != nil {
+		return err
+	}
+	err = requireBankMSP(ctx, bank)
+	if err != nil {
+		return err
+	}
+	if dispute.Status == DisputeResolved {
+		return fmt.Errorf("the dispute of transaction %s is already %s", hash, DisputeResolved)
+	}
+	if outcome != DisputeUpheld && outcome != DisputeRejected {
+		return fmt.Errorf("unknown dispute outcome %q, expected %s or %s", outcome, DisputeUpheld, DisputeRejected)
+	}
+	dispute.Outcome = outcome
+	err = appendDisputeEvent(ctx, dispute, DisputeResolved, note)
+	if err != nil {
+		return err
+	}
+	return ctx.PutStateJSON(DisputePrefix+hash, dispute)
+}
+
+// GetDispute returns the dispute of the transaction hash with its full timeline. Bank of
+// the transaction, or subject to the same consent as the user's data
+func (s *SmartContract) GetDispute(ctx TransactionContextInterface, hash string) (*Dispute, error) {
+	dispute, err := readDispute(ctx, normalizeHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	bank, err := s.GetBankByID(ctx, dispute.BankId)
+	if err != nil {
+		return nil, err
+	}
+	if requireBankMSP(ctx, bank) != nil {
+		err = s.requireConsent(ctx, dispute.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dispute, nil
+}
+
+func readDispute(ctx TransactionContextInterface, hash string) (*Dispute, error) {
+	var dispute Dispute
+	exists, err := ctx.GetStateJSON(DisputePrefix+hash, &dispute)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the transaction %s is not disputed", hash)
+	}
+	return &dispute, nil
+}
@@ -0,0 +1,191 @@
+package smartcontract
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+)
+
+// Transient map entries of the field encryption, named after the Fabric encc sample.
+// ENCKEY and DECKEY are raw AES keys of 16, 24 or 32 bytes. SIGKEY is a PEM PKCS#8 Ed25519
+// private key signing the fields before they are encrypted, VERKEY the matching PEM PKIX
+// public key. Ed25519 is used rather than ECDSA because its signatures are deterministic,
+// so every endorsing peer writes the same value
+const (
+	EncryptionKeyTransientKey   = "ENCKEY"
+	DecryptionKeyTransientKey   = "DECKEY"
+	SigningKeyTransientKey      = "SIGKEY"
+	VerificationKeyTransientKey = "VERKEY"
+)
+
+// sealedField plaintext of an encrypted field, with the signature of the signed variant
+type sealedField struct {
+	Value     string `json:"value"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// encryptableFields returns the sensitive fields of personalData by JSON name
+func encryptableFields(personalData *PersonalData) map[string]*string {
+	return map[string]*string{
+		"name":  &personalData.Name,
+		"email": &personalData.Email,
+	}
+}
+
+// fieldContext binds a ciphertext and its signature to the user and field it belongs to,
+// so values cannot be swapped between users or fields
+func fieldContext(userId string, field string) []byte {
+	return []byte(userId + "/" + field)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AES key: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func parseSigningKey(keyPem []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(keyPem)
+	if block == nil {
+		return nil, fmt.Errorf("signing key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %v", err)
+	}
+	ed25519Key, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key is not an Ed25519 key")
+	}
+	return ed25519Key, nil
+}
+
+func parseVerificationKey(keyPem []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(keyPem)
+	if block == nil {
+		return nil, fmt.Errorf("verification key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse verification key: %v", err)
+	}
+	ed25519Key, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("verification key is not an Ed25519 key")
+	}
+	return ed25519Key, nil
+}
+
+// encryptPersonalData encrypts the name and email of the PersonalData of userId with AES-GCM
+// when ENCKEY is in the transient map, signing them first when SIGKEY is too. The nonce is
+// derived from the transaction ID so that endorsing peers agree on the ciphertext
+func encryptPersonalData(ctx TransactionContextInterface, userId string, personalData *PersonalData) error {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to read transient map: %v", err)
+	}
+	personalData.EncryptedFields = nil
+	key := transientMap[EncryptionKeyTransientKey]
+	if key == nil {
+		return nil
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	var signingKey ed25519.PrivateKey
+	if keyPem := transientMap[SigningKeyTransientKey]; keyPem != nil {
+		signingKey, err = parseSigningKey(keyPem)
+		if err != nil {
+			return err
+		}
+	}
+
+	fields := encryptableFields(personalData)
+	for field, value := range fields {
+		additionalData := fieldContext(userId, field)
+		sealed := sealedField{Value: *value}
+		if signingKey != nil {
+			signature := ed25519.Sign(signingKey, append(additionalData, *value...))
+			sealed.Signature = base64.StdEncoding.EncodeToString(signature)
+		}
+		plaintext, err := json.Marshal(sealed)
+		if err != nil {
+			return err
+		}
+		nonceSeed := sha256.Sum256(append([]byte(ctx.GetStub().GetTxID()+"/"), additionalData...))
+		nonce := nonceSeed[:gcm.NonceSize()]
+		*value = base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, additionalData))
+		personalData.EncryptedFields = append(personalData.EncryptedFields, field)
+	}
+	sort.Strings(personalData.EncryptedFields)
+	return nil
+}
+
+// decryptPersonalData decrypts the encrypted fields of the PersonalData of userId when
+// DECKEY is in the transient map, checking their signatures when VERKEY is too. Without
+// DECKEY the fields are left encrypted
+func decryptPersonalData(ctx TransactionContextInterface, userId string, personalData *PersonalData) error {
+	if len(personalData.EncryptedFields) == 0 {
+		return nil
+	}
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to read transient map: %v", err)
+	}
+	key := transientMap[DecryptionKeyTransientKey]
+	if key == nil {
+		return nil
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	var verificationKey ed25519.PublicKey
+	if keyPem := transientMap[VerificationKeyTransientKey]; keyPem != nil {
+		verificationKey, err = parseVerificationKey(keyPem)
+		if err != nil {
+			return err
+		}
+	}
+
+	fields := encryptableFields(personalData)
+	for _, field := range personalData.EncryptedFields {
+		value, ok := fields[field]
+		if !ok {
+			return fmt.Errorf("unknown encrypted field %s", field)
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(*value)
+		if err != nil || len(ciphertext) < gcm.NonceSize() {
+			return fmt.Errorf("field %s of user %s is not a valid ciphertext", field, userId)
+		}
+		additionalData := fieldContext(userId, field)
+		plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], additionalData)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt field %s of user %s: %v", field, userId, err)
+		}
+		var sealed sealedField
+		err = json.Unmarshal(plaintext, &sealed)
+		if err != nil {
+			return err
+		}
+		if verificationKey != nil {
+			signature, err := base64.StdEncoding.DecodeString(sealed.Signature)
+			if err != nil || !ed25519.Verify(verificationKey, append(additionalData, sealed.Value...), signature) {
+				return fmt.Errorf("signature of field %s of user %s does not verify", field, userId)
+			}
+		}
+		*value = sealed.Value
+	}
+	personalData.EncryptedFields = nil
+	return nil
+}
@@ -0,0 +1,270 @@
+package smartcontract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+const ErasurePrefix = "Erasure_"
+
+// Ways private data was removed: purged with its history when the peer supports it,
+// deleted from the current state otherwise
+const (
+	ErasureMethodPurge  = "purge"
+	ErasureMethodDelete = "delete"
+)
+
+// erasedText replaces free text written by an erased user
+const erasedText = "[erased]"
+
+// ErasureCertificate proof that the personal data of a user was erased, by whom and in
+// which Fabric transaction. The caller's ID holds the subject of its certificate, which is
+// personal data itself, so only its MSP and a digest of the ID are recorded
+type ErasureCertificate struct {
+	UserId         string `json:"user_id"`
+	TxID           string `json:"tx_id"`
+	ErasedBy       string `json:"erased_by"`        // MSP ID of the caller
+	ErasedByDigest string `json:"erased_by_digest"` // hex SHA-256 of the caller's client ID
+	ErasedAt       string `json:"erased_at"`
+	Method         string `json:"method,omitempty" metadata:",optional"` // ErasureMethodPurge or ErasureMethodDelete, empty without private data
+}
+
+// privateDataPurger is implemented by shims which can purge private data from the history
+// of the peers as well, PurgePrivateData being added in Fabric 2.5
+type privateDataPurger interface {
+	PurgePrivateData(collection string, key string) error
+}
+
+// removePrivateData removes key from a private data collection, purging it where the peer
+// supports it. It returns how it was removed, empty when there was nothing to remove
+func removePrivateData(ctx TransactionContextInterface, collection string, key string) (string, error) {
+	value, err := ctx.GetStub().GetPrivateData(collection, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read private data: %v", err)
+	}
+	if value == nil {
+		return "", nil
+	}
+	method := ErasureMethodDelete
+	if purger, ok := ctx.GetStub().(privateDataPurger); ok {
+		err = purger.PurgePrivateData(collection, key)
+		method = ErasureMethodPurge
+	} else {
+		err = ctx.GetStub().DelPrivateData(collection, key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to erase private data: %v", err)
+	}
+	return method, nil
+}
+
+// checkNotErased rejects changes to a user whose personal data was erased
+func checkNotErased(user *User) error {
+	if user.ErasedAt != "" {
+		return fmt.Errorf("the personal data of user %s was erased", user.ID)
+	}
+	return nil
+}
+
+// EraseUserPersonalData erases the personal data of a user on request of the user or the
+// admin organization. The private PersonalData, ScreeningData and name index entry are
+// purged, the user record is replaced by a tombstone keeping only its ID and financial
+// records, free text written by the user is blanked and an ErasureCertificate is recorded
+func (s *SmartContract) EraseUserPersonalData(ctx TransactionContextInterface, id string) (*ErasureCertificate, error) {
+	if requireAdmin(ctx) != nil {
+		err := requireUser(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	user, err := s.readUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = checkNotErased(user)
+	if err != nil {
+		return nil, err
+	}
+	erasedBy, err := ctx.GetCallerMSPID()
+	if err != nil {
+		return nil, err
+	}
+	callerId, err := ctx.GetCallerID()
+	if err != nil {
+		return nil, err
+	}
+	callerDigest := sha256.Sum256([]byte(callerId))
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	certificate := ErasureCertificate{
+		UserId:         id,
+		TxID:           ctx.GetStub().GetTxID(),
+		ErasedBy:       erasedBy,
+		ErasedByDigest: hex.EncodeToString(callerDigest[:]),
+		ErasedAt:       now,
+	}
+
+	err = removeNameIndex(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	certificate.Method, err = removePrivateData(ctx, PersonalDataCollection, id)
+	if err != nil {
+		return nil, err
+	}
+	_, err = removePrivateData(ctx, LookupCollection, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range user.Transfers {
+		if user.Transfers[i].Reference != "" {
+			user.Transfers[i].Reference = erasedText
+		}
+	}
+	user.UpdatedAt = now
+	user.ErasedAt = now
+	err = ctx.PutStateJSON(id, user)
+	if err != nil {
+		return nil, err
+	}
+	// the token account ID embeds the subject of the user's certificate
+	err = unlinkTokenAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.eraseUserText(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ctx.PutStateJSON(ErasurePrefix+id, certificate)
+	if err != nil {
+		return nil, err
+	}
+	return &certificate, nil
+}
+
+// eraseUserText blanks the alert details quoting the user's name, the dispute reasons and
+// notes the user wrote, and the references of the user's transfers, on the Transfer and on
+// the counterparty's TransferEntry. The user's own entries are blanked by the caller
+func (s *SmartContract) eraseUserText(ctx TransactionContextInterface, user *User) error {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(AlertPrefix, prefixRangeEnd(AlertPrefix))
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	var alerts []*Alert
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+		var alert Alert
+		err = json.Unmarshal(queryResponse.Value, &alert)
+		if err != nil {
+			return err
+		}
+		if alert.UserId == user.ID && alert.Detail != "" {
+			alert.Detail = erasedText
+			alerts = append(alerts, &alert)
+		}
+	}
+	for _, alert := range alerts {
+		err = ctx.PutStateJSON(AlertPrefix+alert.ID, alert)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, transaction := range user.Transactions {
+		var dispute Dispute
+		exists, err := ctx.GetStateJSON(DisputePrefix+transaction.Hash, &dispute)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		dispute.Reason = erasedText
+		for _, event := range dispute.Timeline {
+			if event.Status == DisputeOpen {
+				event.Note = erasedText
+			}
+		}
+		err = ctx.PutStateJSON(DisputePrefix+transaction.Hash, dispute)
+		if err != nil {
+			return err
+		}
+	}
+
+	// a counterparty may have several transfers with the user, and a transaction does not
+	// read its own writes, so each counterparty is updated once
+	counterpartyTransfers := map[string]map[string]bool{}
+	for _, entry := range user.Transfers {
+		if entry.Reference == "" {
+			continue
+		}
+		var transfer Transfer
+		exists, err := ctx.GetStateJSON(TransferPrefix+entry.TransferID, &transfer)
+		if err != nil {
+			return err
+		}
+		if exists && transfer.Reference != "" {
+			transfer.Reference = erasedText
+			err = ctx.PutStateJSON(TransferPrefix+entry.TransferID, transfer)
+			if err != nil {
+				return err
+			}
+		}
+		if counterpartyTransfers[entry.Counterparty] == nil {
+			counterpartyTransfers[entry.Counterparty] = map[string]bool{}
+		}
+		counterpartyTransfers[entry.Counterparty][entry.TransferID] = true
+	}
+	var counterparties []string
+	for counterparty := range counterpartyTransfers {
+		counterparties = append(counterparties, counterparty)
+	}
+	sort.Strings(counterparties)
+	for _, counterparty := range counterparties {
+		var other User
+		exists, err := ctx.GetStateJSON(counterparty, &other)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		for i := range other.Transfers {
+			if counterpartyTransfers[counterparty][other.Transfers[i].TransferID] && other.Transfers[i].Reference != "" {
+				other.Transfers[i].Reference = erasedText
+			}
+		}
+		err = ctx.PutStateJSON(counterparty, other)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetErasureCertificate returns the certificate recorded when the personal data of a user
+// was erased
+func (s *SmartContract) GetErasureCertificate(ctx TransactionContextInterface, id string) (*ErasureCertificate, error) {
+	var certificate ErasureCertificate
+	exists, err := ctx.GetStateJSON(ErasurePrefix+id, &certificate)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the personal data of user %s was not erased", id)
+	}
+	return &certificate, nil
+}
@@ -0,0 +1,152 @@
+package smartcontract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// UserDataBundle everything the ledger holds about a user, for data portability requests.
+// The profile is decrypted when DECKEY is in the transient map, its transactions are
+// listed apart under Transactions. PersonalData is withheld from callers outside
+// PersonalDataCollectionMSPID
+type UserDataBundle struct {
+	UserId               string              `json:"user_id"`
+	ExportedAt           string              `json:"exported_at"`
+	TxID                 string              `json:"tx_id"`
+	Profile              *User               `json:"profile"`
+	PersonalData         *PersonalData       `json:"personal_data,omitempty"`
+	PersonalDataWithheld bool                `json:"personal_data_withheld,omitempty"`
+	Transactions         []Transaction       `json:"transactions"`
+	Banks                []*Bank             `json:"banks"`    // banks of the transactions and transfers
+	Disputes             []*Dispute          `json:"disputes"` // disputes of the transactions
+	Consents             []*Consent          `json:"consents"`
+	Archives             []*ArchiveSummary   `json:"archives"` // summaries of the archived transactions
+	ErasureCertificate   *ErasureCertificate `json:"erasure_certificate,omitempty"`
+}
+
+// UserDataExport a UserDataBundle serialized as JSON with the hex encoded SHA-256 of that
+// serialization. The bundle is kept as text so the digest can be checked against the
+// exact bytes without encoding it again
+type UserDataExport struct {
+	Bundle string `json:"bundle"`
+	Digest string `json:"digest"`
+}
+
+// readMemberPersonalData returns the PersonalData of user id, nil when it has none, or
+// reports it withheld when the caller cannot read PersonalDataCollection
+func readMemberPersonalData(ctx TransactionContextInterface, id string) (*PersonalData, bool, error) {
+	mspId, err := ctx.GetCallerMSPID()
+	if err != nil {
+		return nil, false, err
+	}
+	if mspId != PersonalDataCollectionMSPID {
+		return nil, true, nil
+	}
+	personalData, err := readPersonalData(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	return personalData, false, nil
+}
+
+// ExportUserData returns the self-contained bundle of the profile, transactions, bank
+// details, disputes and consents of user id with its digest. Subject to the same consent
+// as the user's data
+func (s *SmartContract) ExportUserData(ctx TransactionContextInterface, id string) (*UserDataExport, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	exportedAt, err := recordedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := UserDataBundle{
+		UserId:       user.ID,
+		ExportedAt:   exportedAt,
+		TxID:         ctx.GetStub().GetTxID(),
+		Transactions: user.Transactions,
+		Banks:        []*Bank{},
+		Disputes:     []*Dispute{},
+		Archives:     []*ArchiveSummary{},
+	}
+	if bundle.Transactions == nil {
+		bundle.Transactions = []Transaction{}
+	}
+	profile := *user
+	profile.Transactions = nil
+	bundle.Profile = &profile
+
+	personalData, withheld, err := readMemberPersonalData(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	bundle.PersonalData = personalData
+	bundle.PersonalDataWithheld = withheld
+
+	bankIds := map[string]bool{}
+	for _, transaction := range user.Transactions {
+		bankIds[transaction.BankId] = true
+
+		var dispute Dispute
+		exists, err := ctx.GetStateJSON(DisputePrefix+transaction.Hash, &dispute)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			bundle.Disputes = append(bundle.Disputes, &dispute)
+		}
+	}
+	for _, entry := range user.Transfers {
+		bankIds[entry.BankId] = true
+	}
+	for bankId := range bankIds {
+		bank, err := s.GetBankByID(ctx, bankId)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Banks = append(bundle.Banks, bank)
+	}
+	sort.Slice(bundle.Banks, func(i, j int) bool {
+		return bundle.Banks[i].ID < bundle.Banks[j].ID
+	})
+
+	for _, archiveId := range user.Archives {
+		var summary ArchiveSummary
+		exists, err := ctx.GetStateJSON(ArchivePrefix+archiveId, &summary)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			bundle.Archives = append(bundle.Archives, &summary)
+		}
+	}
+
+	bundle.Consents, err = s.userConsents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var certificate ErasureCertificate
+	erased, err := ctx.GetStateJSON(ErasurePrefix+id, &certificate)
+	if err != nil {
+		return nil, err
+	}
+	if erased {
+		bundle.ErasureCertificate = &certificate
+	}
+
+	bundleJson, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	digest := sha256.Sum256(bundleJson)
+	return &UserDataExport{
+		Bundle: string(bundleJson),
+		Digest: hex.EncodeToString(digest[:]),
+	}, nil
+}
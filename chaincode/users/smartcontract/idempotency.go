@@ -0,0 +1,108 @@
+package smartcontract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// IdempotencyTransientKey is the transient map entry carrying the client request ID of
+// CreateUser, CreateTransaction and TransferBetweenUsers. Request IDs are scoped to the
+// calling identity. A retried request with the same ID returns the recorded result
+// instead of being applied twice, even when the retry was re-signed or spells the hash
+// differently, but a request ID reused with other arguments is rejected
+const IdempotencyTransientKey = "idempotency_key"
+
+// idempotencyIndex composite key idempotency~function~mspId~callerId~requestId
+const idempotencyIndex = "idempotency"
+
+// idempotencyRecord outcome of the first request made with a request ID. RequestDigest is
+// the SHA-256 of the arguments that request was made with
+type idempotencyRecord struct {
+	TxID          string          `json:"tx_id"`
+	RequestDigest string          `json:"request_digest"`
+	Result        json.RawMessage `json:"result"`
+}
+
+// idempotentReplay looks up the request ID supplied in the transient map. request holds
+// the arguments identifying the request, those a retry may not change. It returns the
+// index key the result is to be recorded under, empty when no ID was supplied, and the
+// record of an earlier request of the caller with the same ID if there is one
+func idempotentReplay(ctx TransactionContextInterface, request interface{}) (string, *idempotencyRecord, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read transient map: %v", err)
+	}
+	requestId := string(transientMap[IdempotencyTransientKey])
+	if requestId == "" {
+		return "", nil, nil
+	}
+
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	if i := strings.LastIndex(function, ":"); i >= 0 {
+		function = function[i+1:]
+	}
+	mspID, err := ctx.GetCallerMSPID()
+	if err != nil {
+		return "", nil, err
+	}
+	callerID, err := ctx.GetCallerID()
+	if err != nil {
+		return "", nil, err
+	}
+	indexKey, err := ctx.GetStub().CreateCompositeKey(idempotencyIndex, []string{function, mspID, callerID, requestId})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", idempotencyIndex, err)
+	}
+
+	var record idempotencyRecord
+	exists, err := ctx.GetStateJSON(indexKey, &record)
+	if err != nil {
+		return "", nil, err
+	}
+	if !exists {
+		return indexKey, nil, nil
+	}
+	digest, err := requestDigest(request)
+	if err != nil {
+		return "", nil, err
+	}
+	if digest != record.RequestDigest {
+		return "", nil, fmt.Errorf("the request ID %s was already used with different arguments", requestId)
+	}
+	return indexKey, &record, nil
+}
+
+// requestDigest returns the hex encoded SHA-256 of the JSON encoding of request
+func requestDigest(request interface{}) (string, error) {
+	requestJson, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(requestJson)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// recordIdempotentResult stores result of request under indexKey for later replays. It
+// does nothing when the request carried no request ID
+func recordIdempotentResult(ctx TransactionContextInterface, indexKey string, request interface{}, result interface{}) error {
+	if indexKey == "" {
+		return nil
+	}
+	digest, err := requestDigest(request)
+	if err != nil {
+		return err
+	}
+	resultJson, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	record := idempotencyRecord{
+		TxID:          ctx.GetStub().GetTxID(),
+		RequestDigest: digest,
+		Result:        resultJson,
+	}
+	return ctx.PutStateJSON(indexKey, record)
+}
@@ -0,0 +1,278 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+const SettlementBatchPrefix = "SettlementBatch_"
+
+const (
+	SettlementProposed  = "proposed"
+	SettlementConfirmed = "confirmed"
+	SettlementSettled   = "settled"
+)
+
+// NetPosition what Debtor owes Creditor in one currency once their transfers are netted
+type NetPosition struct {
+	Currency string `json:"currency"`
+	Debtor   string `json:"debtor"`
+	Creditor string `json:"creditor"`
+	Amount   string `json:"amount"`
+}
+
+// SettlementBatch netting of the interbank transfers between BankA and BankB, BankA < BankB,
+// recorded in a settlement window. It moves from proposed to confirmed once both banks
+// signed it, then to settled
+type SettlementBatch struct {
+	ID          string         `json:"id"`
+	BankA       string         `json:"bank_a"`
+	BankB       string         `json:"bank_b"`
+	WindowStart string         `json:"window_start"`
+	WindowEnd   string         `json:"window_end"`
+	Positions   []*NetPosition `json:"positions"`
+	TransferIDs []string       `json:"transfer_ids"`
+	Status      string         `json:"status"`
+	ConfirmedBy []string       `json:"confirmed_by"`
+	CreatedAt   string         `json:"created_at"`
+	ConfirmedAt string         `json:"confirmed_at,omitempty" metadata:",optional"`
+	SettledAt   string         `json:"settled_at,omitempty" metadata:",optional"`
+}
+
+// SettlementBatchPayload returns the bytes a bank signs to confirm batch, covering
+// everything fixed when the batch was proposed
+func SettlementBatchPayload(batch *SettlementBatch) ([]byte, error) {
+	return json.Marshal(struct {
+		ID          string         `json:"id"`
+		BankA       string         `json:"bank_a"`
+		BankB       string         `json:"bank_b"`
+		WindowStart string         `json:"window_start"`
+		WindowEnd   string         `json:"window_end"`
+		Positions   []*NetPosition `json:"positions"`
+		TransferIDs []string       `json:"transfer_ids"`
+	}{batch.ID, batch.BankA, batch.BankB, batch.WindowStart, batch.WindowEnd, batch.Positions, batch.TransferIDs})
+}
+
+// ProposeSettlementBatches nets the interbank transfers recorded from windowStart to
+// windowEnd inclusive which are not part of a batch yet, and proposes one batch per pair
+// of banks. Admin only
+func (s *SmartContract) ProposeSettlementBatches(ctx TransactionContextInterface, windowStart string, windowEnd string) ([]*SettlementBatch, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start, err := time.Parse("2006-01-02", windowStart)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window start %q", windowStart)
+	}
+	end, err := time.Parse("2006-01-02", windowEnd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window end %q", windowEnd)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("window end %s is before window start %s", windowEnd, windowStart)
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByRange(TransferPrefix, prefixRangeEnd(TransferPrefix))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	batches := map[string]*SettlementBatch{}
+	// pair -> currency -> what BankA owes BankB, negative when BankB owes BankA
+	nets := map[string]map[string]*big.Rat{}
+	var transfers []*Transfer
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var transfer Transfer
+		err = json.Unmarshal(queryResponse.Value, &transfer)
+		if err != nil {
+			return nil, err
+		}
+		if transfer.ToBankId == "" || transfer.ToBankId == transfer.BankId || transfer.SettlementBatchID != "" {
+			continue
+		}
+		recordedDate := transfer.CreatedAt
+		if len(recordedDate) > len("2006-01-02") {
+			recordedDate = recordedDate[:len("2006-01-02")]
+		}
+		if recordedDate < windowStart || recordedDate > windowEnd {
+			continue
+		}
+
+		bankA, bankB := transfer.BankId, transfer.ToBankId
+		if bankB < bankA {
+			bankA, bankB = bankB, bankA
+		}
+		pair := bankA + "_" + bankB
+		batch, ok := batches[pair]
+		if !ok {
+			batch = &SettlementBatch{
+				ID:          ctx.GetStub().GetTxID() + "_" + pair,
+				BankA:       bankA,
+				BankB:       bankB,
+				WindowStart: windowStart,
+				WindowEnd:   windowEnd,
+				Positions:   []*NetPosition{},
+				Status:      SettlementProposed,
+				ConfirmedBy: []string{},
+				CreatedAt:   now,
+			}
+			batches[pair] = batch
+			nets[pair] = map[string]*big.Rat{}
+		}
+		amount, err := parseAmount(transfer.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if transfer.BankId != bankA {
+			amount.Neg(amount)
+		}
+		net, ok := nets[pair][transfer.Currency]
+		if !ok {
+			net = new(big.Rat)
+			nets[pair][transfer.Currency] = net
+		}
+		net.Add(net, amount)
+
+		batch.TransferIDs = append(batch.TransferIDs, transfer.ID)
+		transfer.SettlementBatchID = batch.ID
+		transfers = append(transfers, &transfer)
+	}
+
+	var pairs []string
+	for pair := range batches {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+
+	result := []*SettlementBatch{}
+	for _, pair := range pairs {
+		batch := batches[pair]
+		var currencies []string
+		for currency := range nets[pair] {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+		for _, currency := range currencies {
+			net := nets[pair][currency]
+			position := &NetPosition{Currency: currency, Debtor: batch.BankA, Creditor: batch.BankB}
+			switch net.Sign() {
+			case 0:
+				continue
+			case -1:
+				position.Debtor, position.Creditor = batch.BankB, batch.BankA
+				net.Neg(net)
+			}
+			position.Amount = formatAmount(net)
+			batch.Positions = append(batch.Positions, position)
+		}
+		err = ctx.PutStateJSON(SettlementBatchPrefix+batch.ID, batch)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, batch)
+	}
+	for _, transfer := range transfers {
+		err = ctx.PutStateJSON(TransferPrefix+transfer.ID, transfer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GetSettlementBatch returns the settlement batch recorded under batchId
+func (s *SmartContract) GetSettlementBatch(ctx TransactionContextInterface, batchId string) (*SettlementBatch, error) {
+	var batch SettlementBatch
+	exists, err := ctx.GetStateJSON(SettlementBatchPrefix+batchId, &batch)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the settlement batch %s does not exist", batchId)
+	}
+	return &batch, nil
+}
+
+// ConfirmSettlementBatch records the agreement of bankId to a proposed batch. signature is
+// made with the bank's registered key over SettlementBatchPayload. The batch is confirmed
+// once both of its banks agreed
+func (s *SmartContract) ConfirmSettlementBatch(ctx TransactionContextInterface, bankId string, batchId string, signature string) error {
+	batch, err := s.GetSettlementBatch(ctx, batchId)
+	if err != nil {
+		return err
+	}
+	if batch.Status != SettlementProposed {
+		return fmt.Errorf("the settlement batch %s is %s, not %s", batchId, batch.Status, SettlementProposed)
+	}
+	if bankId != batch.BankA && bankId != batch.BankB {
+		return fmt.Errorf("the bank %s is not part of settlement batch %s", bankId, batchId)
+	}
+	for _, confirmed := range batch.ConfirmedBy {
+		if confirmed == bankId {
+			return fmt.Errorf("the bank %s already confirmed settlement batch %s", bankId, batchId)
+		}
+	}
+	bank, err := s.GetBankByID(ctx, bankId)
+	if err != nil {
+		return err
+	}
+	if bank.PublicKey == "" {
+		return fmt.Errorf("the bank %s has no registered public key", bankId)
+	}
+	payload, err := SettlementBatchPayload(batch)
+	if err != nil {
+		return err
+	}
+	err = verifySignature(bank.PublicKey, payload, signature)
+	if err != nil {
+		return fmt.Errorf("settlement batch %s is not signed by bank %s: %v", batchId, bankId, err)
+	}
+
+	batch.ConfirmedBy = append(batch.ConfirmedBy, bankId)
+	if len(batch.ConfirmedBy) == 2 {
+		now, err := recordedAt(ctx)
+		if err != nil {
+			return err
+		}
+		batch.Status = SettlementConfirmed
+		batch.ConfirmedAt = now
+	}
+	return ctx.PutStateJSON(SettlementBatchPrefix+batchId, batch)
+}
+
+// SettleSettlementBatch marks a confirmed batch as settled once the net positions were
+// paid. Admin only
+func (s *SmartContract) SettleSettlementBatch(ctx TransactionContextInterface, batchId string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	batch, err := s.GetSettlementBatch(ctx, batchId)
+	if err != nil {
+		return err
+	}
+	if batch.Status != SettlementConfirmed {
+		return fmt.Errorf("the settlement batch %s is %s, not %s", batchId, batch.Status, SettlementConfirmed)
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	batch.Status = SettlementSettled
+	batch.SettledAt = now
+	return ctx.PutStateJSON(SettlementBatchPrefix+batchId, batch)
+}
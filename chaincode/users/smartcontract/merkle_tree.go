@@ -0,0 +1,191 @@
+package smartcontract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"users/merkle"
+	"users/verifier"
+)
+
+const MerkleTreePrefix = "MerkleTree_"
+
+// merkleNodeIndex composite key merkleNode~userId~level~index holding the hex encoded
+// root of the perfect subtree over the 2^level leaves from leaf index<<level, the leaves
+// being level 0. Level and index are zero padded so keys sort. Nodes are only removed
+// with the user, archived transactions stay provable
+const merkleNodeIndex = "merkleNode"
+
+// merkleHashIndex composite key merkleHash~userId~hash holding the index of the leaf of
+// the transaction hash
+const merkleHashIndex = "merkleHash"
+
+// UserMerkleTree Merkle tree over the hashes of the transactions recorded for a user, in
+// recording order, see verifier.TransactionLeafHash. The frontier grows the tree and its
+// completed subtrees are kept under merkleNodeIndex for proofs, Root is updated by every
+// CreateTransaction
+type UserMerkleTree struct {
+	UserId    string          `json:"user_id"`
+	Root      string          `json:"root"`
+	Frontier  merkle.Frontier `json:"frontier"`
+	UpdatedAt string          `json:"updated_at"`
+}
+
+func merkleNodeKey(ctx TransactionContextInterface, userId string, level int, index int) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(merkleNodeIndex, []string{userId, fmt.Sprintf("%02d", level), fmt.Sprintf("%010d", index)})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", merkleNodeIndex, err)
+	}
+	return key, nil
+}
+
+func merkleHashKey(ctx TransactionContextInterface, userId string, hash string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(merkleHashIndex, []string{userId, normalizeHash(hash)})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", merkleHashIndex, err)
+	}
+	return key, nil
+}
+
+// appendTransactionLeaf adds hash to the Merkle tree of user. The tree of a user with
+// transactions recorded before it existed starts with their hashes
+func (s *SmartContract) appendTransactionLeaf(ctx TransactionContextInterface, user *User, hash string) error {
+	tree := UserMerkleTree{UserId: user.ID}
+	exists, err := ctx.GetStateJSON(MerkleTreePrefix+user.ID, &tree)
+	if err != nil {
+		return err
+	}
+	hashes := []string{hash}
+	if !exists {
+		hashes = nil
+		for _, transaction := range user.Transactions {
+			hashes = append(hashes, transaction.Hash)
+		}
+		hashes = append(hashes, hash)
+	}
+	for _, leafHash := range hashes {
+		key, err := merkleHashKey(ctx, user.ID, leafHash)
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().PutState(key, []byte(strconv.Itoa(tree.Frontier.Size)))
+		if err != nil {
+			return err
+		}
+		nodes, err := tree.Frontier.AppendNodes(verifier.TransactionLeafHash(leafHash))
+		if err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			key, err := merkleNodeKey(ctx, user.ID, node.Level, node.Index)
+			if err != nil {
+				return err
+			}
+			err = ctx.GetStub().PutState(key, []byte(hex.EncodeToString(node.Hash)))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	root, err := tree.Frontier.Root()
+	if err != nil {
+		return err
+	}
+	tree.Root = hex.EncodeToString(root)
+	tree.UpdatedAt, err = recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.PutStateJSON(MerkleTreePrefix+user.ID, tree)
+}
+
+// deleteMerkleTree deletes the Merkle tree of userId with its nodes and leaf indexes
+func (s *SmartContract) deleteMerkleTree(ctx TransactionContextInterface, userId string) error {
+	for _, index := range []string{merkleNodeIndex, merkleHashIndex} {
+		keys, err := s.indexKeys(ctx, index, []string{userId})
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			err = ctx.GetStub().DelState(key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return ctx.GetStub().DelState(MerkleTreePrefix + userId)
+}
+
+// GetUserMerkleTree returns the Merkle tree of the transactions of userId. Subject to the
+// same consent as the user's data
+func (s *SmartContract) GetUserMerkleTree(ctx TransactionContextInterface, userId string) (*UserMerkleTree, error) {
+	err := s.requireConsent(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	var tree UserMerkleTree
+	exists, err := ctx.GetStateJSON(MerkleTreePrefix+userId, &tree)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the user %s has no Merkle tree", userId)
+	}
+	return &tree, nil
+}
+
+// GetTransactionProof returns the sibling path proving the transaction hash is part of
+// the transactions recorded for userId, against the current root. It can be checked
+// offline with verifier.Verify. Subject to the same consent as the user's data
+func (s *SmartContract) GetTransactionProof(ctx TransactionContextInterface, userId string, hash string) (*verifier.TransactionProof, error) {
+	tree, err := s.GetUserMerkleTree(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	hash = normalizeHash(hash)
+	key, err := merkleHashKey(ctx, userId, hash)
+	if err != nil {
+		return nil, err
+	}
+	leafIndex, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if leafIndex == nil {
+		return nil, fmt.Errorf("the transaction %s is not recorded for user %s", hash, userId)
+	}
+	index, err := strconv.Atoi(string(leafIndex))
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := merkle.ProveFromNodes(tree.Frontier.Size, index, func(level int, index int) ([]byte, error) {
+		key, err := merkleNodeKey(ctx, userId, level, index)
+		if err != nil {
+			return nil, err
+		}
+		node, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if node == nil {
+			return nil, fmt.Errorf("the Merkle tree of user %s has no node %d at level %d", userId, index, level)
+		}
+		return hex.DecodeString(string(node))
+	})
+	if err != nil {
+		return nil, err
+	}
+	root, err := merkle.RootFromProof(verifier.TransactionLeafHash(hash), proof)
+	if err != nil || hex.EncodeToString(root) != tree.Root {
+		return nil, fmt.Errorf("the Merkle nodes of user %s do not match its root", userId)
+	}
+	return &verifier.TransactionProof{
+		UserId: userId,
+		Hash:   hash,
+		Root:   tree.Root,
+		Proof:  *proof,
+	}, nil
+}
@@ -0,0 +1,2920 @@
+// Code generated by metadatagen from the transaction function signatures. DO NOT EDIT.
+
+package smartcontract
+
+// contractMetadata contract-metadata/metadata.json, see InstallContractMetadata
+const contractMetadata = `{
+  "info": {
+    "title": "undefined",
+    "version": "latest"
+  },
+  "contracts": {
+    "SmartContract": {
+      "info": {
+        "title": "SmartContract",
+        "version": "latest"
+      },
+      "name": "SmartContract",
+      "transactions": [
+        {
+          "parameters": [
+            {
+              "name": "mspId",
+              "schema": {
+                "type": "string",
+                "maxLength": 64,
+                "minLength": 1
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "AddUserMSP"
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 64,
+                "pattern": "^[0-9A-Za-z_-]+$"
+              }
+            },
+            {
+              "name": "name",
+              "schema": {
+                "type": "string",
+                "maxLength": 256,
+                "minLength": 1
+              }
+            },
+            {
+              "name": "identifiers",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "action",
+              "schema": {
+                "type": "string",
+                "pattern": "^(block|flag)$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "AddWatchlistEntry"
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "beforeDate",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ArchiveTransactions",
+          "returns": {
+            "$ref": "#/components/schemas/ArchiveResult"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "CancelStandingOrder"
+        },
+        {
+          "parameters": [
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            },
+            {
+              "name": "category",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "CategorizeTransaction"
+        },
+        {
+          "parameters": [
+            {
+              "name": "bankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "batchId",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "signature",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ConfirmSettlementBatch"
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "amount",
+              "schema": {
+                "type": "string",
+                "maxLength": 32,
+                "pattern": "^[0-9]+(\\.[0-9]{1,8})?$"
+              }
+            },
+            {
+              "name": "currency",
+              "schema": {
+                "type": "string",
+                "pattern": "^[A-Z]{3}$"
+              }
+            },
+            {
+              "name": "bankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "schedule",
+              "schema": {
+                "type": "string",
+                "pattern": "^(daily|weekly|monthly)$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "CreateStandingOrder",
+          "returns": {
+            "type": "string"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            },
+            {
+              "name": "amount",
+              "schema": {
+                "type": "string",
+                "maxLength": 32,
+                "pattern": "^[0-9]+(\\.[0-9]{1,8})?$"
+              }
+            },
+            {
+              "name": "currency",
+              "schema": {
+                "type": "string",
+                "pattern": "^[A-Z]{3}$"
+              }
+            },
+            {
+              "name": "date",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            },
+            {
+              "name": "bankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "reference",
+              "schema": {
+                "type": "string",
+                "maxLength": 64
+              }
+            },
+            {
+              "name": "signature",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "CreateTransaction",
+          "returns": {
+            "type": "string"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "CreateUser"
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "DeleteUser"
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "EraseUserPersonalData",
+          "returns": {
+            "$ref": "#/components/schemas/ErasureCertificate"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "asOf",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ExecuteDueStandingOrders",
+          "returns": {
+            "$ref": "#/components/schemas/StandingOrderExecution"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ExportUserData",
+          "returns": {
+            "$ref": "#/components/schemas/UserDataExport"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "currency",
+              "schema": {
+                "type": "string",
+                "pattern": "^[A-Z]{3}$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetAMLRule",
+          "returns": {
+            "$ref": "#/components/schemas/AMLRule"
+          }
+        },
+        {
+          "tag": [
+            "submit"
+          ],
+          "name": "GetAllUsers",
+          "returns": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/User"
+            }
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "version",
+              "schema": {
+                "type": "integer",
+                "format": "int64"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetArchiveKey",
+          "returns": {
+            "$ref": "#/components/schemas/ArchiveKey"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetArchiveSummary",
+          "returns": {
+            "$ref": "#/components/schemas/ArchiveSummary"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "bankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetBankByID",
+          "returns": {
+            "$ref": "#/components/schemas/Bank"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetDispute",
+          "returns": {
+            "$ref": "#/components/schemas/Dispute"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetErasureCertificate",
+          "returns": {
+            "$ref": "#/components/schemas/ErasureCertificate"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "batchId",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetSettlementBatch",
+          "returns": {
+            "$ref": "#/components/schemas/SettlementBatch"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetStandingOrder",
+          "returns": {
+            "$ref": "#/components/schemas/StandingOrder"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetTokenAccount",
+          "returns": {
+            "$ref": "#/components/schemas/TokenAccount"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetTransactionProof",
+          "returns": {
+            "$ref": "#/components/schemas/TransactionProof"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "transferId",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetTransfer",
+          "returns": {
+            "$ref": "#/components/schemas/Transfer"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetUser",
+          "returns": {
+            "$ref": "#/components/schemas/User"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetUserBalances",
+          "returns": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Balance"
+            }
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "accountId",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetUserByTokenAccount",
+          "returns": {
+            "$ref": "#/components/schemas/User"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetUserByTransactionHash",
+          "returns": {
+            "$ref": "#/components/schemas/User"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetUserMerkleTree",
+          "returns": {
+            "$ref": "#/components/schemas/UserMerkleTree"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetUserPersonalData",
+          "returns": {
+            "$ref": "#/components/schemas/PersonalData"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "month",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetUserStatement",
+          "returns": {
+            "$ref": "#/components/schemas/Statement"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GetUserTokenBalance",
+          "returns": {
+            "type": "integer",
+            "format": "int64"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "mspId",
+              "schema": {
+                "type": "string",
+                "maxLength": 64,
+                "minLength": 1
+              }
+            },
+            {
+              "name": "expiresOn",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "GrantConsent"
+        },
+        {
+          "tag": [
+            "submit"
+          ],
+          "name": "InitLedger"
+        },
+        {
+          "parameters": [
+            {
+              "name": "fromUserId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "toUserId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "amount",
+              "schema": {
+                "type": "string",
+                "maxLength": 32,
+                "pattern": "^[0-9]+(\\.[0-9]{1,8})?$"
+              }
+            },
+            {
+              "name": "currency",
+              "schema": {
+                "type": "string",
+                "pattern": "^[A-Z]{3}$"
+              }
+            },
+            {
+              "name": "fromBankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "toBankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "reference",
+              "schema": {
+                "type": "string",
+                "maxLength": 140
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "InterbankTransfer",
+          "returns": {
+            "type": "string"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "LinkTokenAccount",
+          "returns": {
+            "$ref": "#/components/schemas/TokenAccount"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "status",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ListAlerts",
+          "returns": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Alert"
+            }
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "startDate",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            },
+            {
+              "name": "endDate",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            },
+            {
+              "name": "dateField",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "pageSize",
+              "schema": {
+                "type": "integer",
+                "format": "int64"
+              }
+            },
+            {
+              "name": "bookmark",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ListMyBankTransactions",
+          "returns": {
+            "$ref": "#/components/schemas/TransactionPage"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "bankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "startDate",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            },
+            {
+              "name": "endDate",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            },
+            {
+              "name": "dateField",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "pageSize",
+              "schema": {
+                "type": "integer",
+                "format": "int64"
+              }
+            },
+            {
+              "name": "bookmark",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ListTransactionsByBank",
+          "returns": {
+            "$ref": "#/components/schemas/TransactionPage"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "startDate",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            },
+            {
+              "name": "endDate",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            },
+            {
+              "name": "dateField",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "pageSize",
+              "schema": {
+                "type": "integer",
+                "format": "int64"
+              }
+            },
+            {
+              "name": "bookmark",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ListTransactionsByDateRange",
+          "returns": {
+            "$ref": "#/components/schemas/TransactionPage"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "tag",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ListTransactionsByTag",
+          "returns": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Transaction"
+            }
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ListUserConsents",
+          "returns": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Consent"
+            }
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ListUserTransactions",
+          "returns": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Transaction"
+            }
+          }
+        },
+        {
+          "tag": [
+            "submit"
+          ],
+          "name": "ListWatchlist",
+          "returns": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/WatchlistEntry"
+            }
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            },
+            {
+              "name": "reason",
+              "schema": {
+                "type": "string",
+                "maxLength": 500,
+                "minLength": 1
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "OpenDispute"
+        },
+        {
+          "parameters": [
+            {
+              "name": "windowStart",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            },
+            {
+              "name": "windowEnd",
+              "schema": {
+                "type": "string",
+                "format": "date"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ProposeSettlementBatches",
+          "returns": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/SettlementBatch"
+            }
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "publicKey",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "RegisterArchiveKey"
+        },
+        {
+          "parameters": [
+            {
+              "name": "bankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "publicKey",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "RegisterBankKey"
+        },
+        {
+          "parameters": [
+            {
+              "name": "mspId",
+              "schema": {
+                "type": "string",
+                "maxLength": 64,
+                "minLength": 1
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "RemoveUserMSP"
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 64,
+                "pattern": "^[0-9A-Za-z_-]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "RemoveWatchlistEntry"
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "note",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ResolveAlert"
+        },
+        {
+          "parameters": [
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            },
+            {
+              "name": "outcome",
+              "schema": {
+                "type": "string",
+                "pattern": "^(upheld|rejected)$"
+              }
+            },
+            {
+              "name": "note",
+              "schema": {
+                "type": "string",
+                "maxLength": 500
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ResolveDispute"
+        },
+        {
+          "parameters": [
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            },
+            {
+              "name": "response",
+              "schema": {
+                "type": "string",
+                "maxLength": 500
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "RespondDispute"
+        },
+        {
+          "parameters": [
+            {
+              "name": "userId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "mspId",
+              "schema": {
+                "type": "string",
+                "maxLength": 64,
+                "minLength": 1
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "RevokeConsent"
+        },
+        {
+          "parameters": [
+            {
+              "name": "bankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "newPublicKey",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "signature",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "RotateBankKey"
+        },
+        {
+          "parameters": [
+            {
+              "name": "name",
+              "schema": {
+                "type": "string",
+                "maxLength": 256,
+                "minLength": 1
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "ScreenName",
+          "returns": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/SanctionsMatch"
+            }
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "prefix",
+              "schema": {
+                "type": "string",
+                "maxLength": 64,
+                "minLength": 1
+              }
+            },
+            {
+              "name": "pageSize",
+              "schema": {
+                "type": "integer",
+                "format": "int64"
+              }
+            },
+            {
+              "name": "bookmark",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "SearchUsersByNamePrefix",
+          "returns": {
+            "$ref": "#/components/schemas/UserPage"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "currency",
+              "schema": {
+                "type": "string",
+                "pattern": "^[A-Z]{3}$"
+              }
+            },
+            {
+              "name": "threshold",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "dailyLimit",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "SetAMLRule"
+        },
+        {
+          "parameters": [
+            {
+              "name": "bankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "mspId",
+              "schema": {
+                "type": "string",
+                "maxLength": 64,
+                "minLength": 1
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "SetBankMSPID"
+        },
+        {
+          "parameters": [
+            {
+              "name": "batchId",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "SettleSettlementBatch"
+        },
+        {
+          "parameters": [
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            },
+            {
+              "name": "tags",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "TagTransaction"
+        },
+        {
+          "parameters": [
+            {
+              "name": "fromUserId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "toUserId",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            },
+            {
+              "name": "amount",
+              "schema": {
+                "type": "string",
+                "maxLength": 32,
+                "pattern": "^[0-9]+(\\.[0-9]{1,8})?$"
+              }
+            },
+            {
+              "name": "currency",
+              "schema": {
+                "type": "string",
+                "pattern": "^[A-Z]{3}$"
+              }
+            },
+            {
+              "name": "bankId",
+              "schema": {
+                "type": "string",
+                "pattern": "^[0-9]{8}$"
+              }
+            },
+            {
+              "name": "reference",
+              "schema": {
+                "type": "string",
+                "maxLength": 140
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "TransferBetweenUsers",
+          "returns": {
+            "type": "string"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "UpdateUser"
+        },
+        {
+          "parameters": [
+            {
+              "name": "id",
+              "schema": {
+                "type": "string",
+                "maxLength": 20,
+                "pattern": "^[0-9]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "UserExists",
+          "returns": {
+            "type": "boolean"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            },
+            {
+              "name": "proof",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "VerifyArchivedTransaction",
+          "returns": {
+            "type": "boolean"
+          }
+        },
+        {
+          "parameters": [
+            {
+              "name": "hash",
+              "schema": {
+                "type": "string",
+                "maxLength": 66,
+                "pattern": "^(0x)?[0-9a-fA-F]+$"
+              }
+            }
+          ],
+          "tag": [
+            "submit"
+          ],
+          "name": "VerifyTransaction",
+          "returns": {
+            "type": "boolean"
+          }
+        }
+      ],
+      "default": true
+    },
+    "org.hyperledger.fabric": {
+      "info": {
+        "title": "org.hyperledger.fabric",
+        "version": "latest"
+      },
+      "name": "org.hyperledger.fabric",
+      "transactions": [
+        {
+          "tag": [
+            "evaluate"
+          ],
+          "name": "GetMetadata",
+          "returns": {
+            "type": "string"
+          }
+        }
+      ],
+      "default": false
+    }
+  },
+  "components": {
+    "schemas": {
+      "AMLRule": {
+        "$id": "AMLRule",
+        "properties": {
+          "currency": {
+            "type": "string"
+          },
+          "daily_limit": {
+            "type": "string"
+          },
+          "threshold": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "currency",
+          "threshold",
+          "daily_limit"
+        ],
+        "additionalProperties": false
+      },
+      "Alert": {
+        "$id": "Alert",
+        "properties": {
+          "amount": {
+            "type": "string"
+          },
+          "created_at": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "detail": {
+            "type": "string"
+          },
+          "id": {
+            "type": "string"
+          },
+          "limit": {
+            "type": "string"
+          },
+          "note": {
+            "type": "string"
+          },
+          "resolved_at": {
+            "type": "string"
+          },
+          "resolved_by": {
+            "type": "string"
+          },
+          "rule": {
+            "type": "string"
+          },
+          "status": {
+            "type": "string"
+          },
+          "transaction_hash": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "id",
+          "user_id",
+          "transaction_hash",
+          "rule",
+          "currency",
+          "amount",
+          "limit",
+          "status",
+          "created_at"
+        ],
+        "additionalProperties": false
+      },
+      "ArchiveKey": {
+        "$id": "ArchiveKey",
+        "properties": {
+          "public_key": {
+            "type": "string"
+          },
+          "registered_at": {
+            "type": "string"
+          },
+          "version": {
+            "type": "integer",
+            "format": "int64"
+          }
+        },
+        "required": [
+          "version",
+          "public_key",
+          "registered_at"
+        ],
+        "additionalProperties": false
+      },
+      "ArchiveResult": {
+        "$id": "ArchiveResult",
+        "properties": {
+          "summary": {
+            "$ref": "ArchiveSummary"
+          },
+          "transactions": {
+            "type": "array",
+            "items": {
+              "$ref": "ArchivedTransaction"
+            }
+          }
+        },
+        "required": [
+          "summary",
+          "transactions"
+        ],
+        "additionalProperties": false
+      },
+      "ArchiveSummary": {
+        "$id": "ArchiveSummary",
+        "properties": {
+          "archived_at": {
+            "type": "string"
+          },
+          "archived_by": {
+            "type": "string"
+          },
+          "before_date": {
+            "type": "string"
+          },
+          "count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "id": {
+            "type": "string"
+          },
+          "key_version": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "merkle_root": {
+            "type": "string"
+          },
+          "signature": {
+            "type": "string"
+          },
+          "totals": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            }
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "id",
+          "user_id",
+          "before_date",
+          "count",
+          "totals",
+          "merkle_root",
+          "archived_by",
+          "archived_at",
+          "key_version",
+          "signature"
+        ],
+        "additionalProperties": false
+      },
+      "ArchivedTransaction": {
+        "$id": "ArchivedTransaction",
+        "properties": {
+          "amount": {
+            "type": "string"
+          },
+          "bank_id": {
+            "type": "string"
+          },
+          "category": {
+            "type": "string"
+          },
+          "created_at": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "date": {
+            "type": "string"
+          },
+          "hash": {
+            "type": "string"
+          },
+          "proof": {
+            "$ref": "Proof"
+          },
+          "reference": {
+            "type": "string"
+          },
+          "signature": {
+            "type": "string"
+          },
+          "standing_order_id": {
+            "type": "string"
+          },
+          "standing_order_run": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "tags": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "token_transfer": {
+            "$ref": "TokenTransfer"
+          },
+          "updated_at": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "hash",
+          "amount",
+          "currency",
+          "date",
+          "bank_id",
+          "proof"
+        ],
+        "additionalProperties": false
+      },
+      "Balance": {
+        "$id": "Balance",
+        "properties": {
+          "amount": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "currency",
+          "amount"
+        ],
+        "additionalProperties": false
+      },
+      "Bank": {
+        "$id": "Bank",
+        "properties": {
+          "created_at": {
+            "type": "string"
+          },
+          "id": {
+            "type": "string"
+          },
+          "key_updated_at": {
+            "type": "string"
+          },
+          "key_version": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "msp_id": {
+            "type": "string"
+          },
+          "name": {
+            "type": "string"
+          },
+          "public_key": {
+            "type": "string"
+          },
+          "transaction_count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "updated_at": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "id",
+          "name",
+          "transaction_count"
+        ],
+        "additionalProperties": false
+      },
+      "BankStatement": {
+        "$id": "BankStatement",
+        "properties": {
+          "bank_id": {
+            "type": "string"
+          },
+          "count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "totals": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            }
+          }
+        },
+        "required": [
+          "bank_id",
+          "count",
+          "totals"
+        ],
+        "additionalProperties": false
+      },
+      "CategoryStatement": {
+        "$id": "CategoryStatement",
+        "properties": {
+          "category": {
+            "type": "string"
+          },
+          "count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "totals": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            }
+          }
+        },
+        "required": [
+          "category",
+          "count",
+          "totals"
+        ],
+        "additionalProperties": false
+      },
+      "Consent": {
+        "$id": "Consent",
+        "properties": {
+          "expires_on": {
+            "type": "string"
+          },
+          "granted_at": {
+            "type": "string"
+          },
+          "msp_id": {
+            "type": "string"
+          },
+          "revoked_at": {
+            "type": "string"
+          },
+          "status": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "user_id",
+          "msp_id",
+          "expires_on",
+          "status",
+          "granted_at"
+        ],
+        "additionalProperties": false
+      },
+      "Dispute": {
+        "$id": "Dispute",
+        "properties": {
+          "bank_id": {
+            "type": "string"
+          },
+          "outcome": {
+            "type": "string"
+          },
+          "reason": {
+            "type": "string"
+          },
+          "status": {
+            "type": "string"
+          },
+          "timeline": {
+            "type": "array",
+            "items": {
+              "$ref": "DisputeEvent"
+            }
+          },
+          "transaction_hash": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "transaction_hash",
+          "user_id",
+          "bank_id",
+          "reason",
+          "status",
+          "timeline"
+        ],
+        "additionalProperties": false
+      },
+      "DisputeEvent": {
+        "$id": "DisputeEvent",
+        "properties": {
+          "actor": {
+            "type": "string"
+          },
+          "at": {
+            "type": "string"
+          },
+          "note": {
+            "type": "string"
+          },
+          "status": {
+            "type": "string"
+          },
+          "tx_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "status",
+          "actor",
+          "tx_id",
+          "at"
+        ],
+        "additionalProperties": false
+      },
+      "ErasureCertificate": {
+        "$id": "ErasureCertificate",
+        "properties": {
+          "erased_at": {
+            "type": "string"
+          },
+          "erased_by": {
+            "type": "string"
+          },
+          "erased_by_digest": {
+            "type": "string"
+          },
+          "method": {
+            "type": "string"
+          },
+          "tx_id": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "user_id",
+          "tx_id",
+          "erased_by",
+          "erased_by_digest",
+          "erased_at"
+        ],
+        "additionalProperties": false
+      },
+      "Frontier": {
+        "$id": "Frontier",
+        "properties": {
+          "nodes": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "size": {
+            "type": "integer",
+            "format": "int64"
+          }
+        },
+        "required": [
+          "size",
+          "nodes"
+        ],
+        "additionalProperties": false
+      },
+      "NetPosition": {
+        "$id": "NetPosition",
+        "properties": {
+          "amount": {
+            "type": "string"
+          },
+          "creditor": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "debtor": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "currency",
+          "debtor",
+          "creditor",
+          "amount"
+        ],
+        "additionalProperties": false
+      },
+      "PersonalData": {
+        "$id": "PersonalData",
+        "properties": {
+          "address": {
+            "type": "string"
+          },
+          "birth_date": {
+            "type": "string"
+          },
+          "email": {
+            "type": "string"
+          },
+          "encrypted_fields": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "full_name": {
+            "type": "string"
+          },
+          "name": {
+            "type": "string"
+          },
+          "national_id": {
+            "type": "string"
+          },
+          "phone": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "name",
+          "email"
+        ],
+        "additionalProperties": false
+      },
+      "Proof": {
+        "$id": "Proof",
+        "properties": {
+          "index": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "siblings": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "size": {
+            "type": "integer",
+            "format": "int64"
+          }
+        },
+        "required": [
+          "index",
+          "size",
+          "siblings"
+        ],
+        "additionalProperties": false
+      },
+      "SanctionsMatch": {
+        "$id": "SanctionsMatch",
+        "properties": {
+          "action": {
+            "type": "string"
+          },
+          "entry_id": {
+            "type": "string"
+          },
+          "name": {
+            "type": "string"
+          },
+          "score": {
+            "type": "number",
+            "format": "double"
+          }
+        },
+        "required": [
+          "entry_id",
+          "name",
+          "action",
+          "score"
+        ],
+        "additionalProperties": false
+      },
+      "SettlementBatch": {
+        "$id": "SettlementBatch",
+        "properties": {
+          "bank_a": {
+            "type": "string"
+          },
+          "bank_b": {
+            "type": "string"
+          },
+          "confirmed_at": {
+            "type": "string"
+          },
+          "confirmed_by": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "created_at": {
+            "type": "string"
+          },
+          "id": {
+            "type": "string"
+          },
+          "positions": {
+            "type": "array",
+            "items": {
+              "$ref": "NetPosition"
+            }
+          },
+          "settled_at": {
+            "type": "string"
+          },
+          "status": {
+            "type": "string"
+          },
+          "transfer_ids": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "window_end": {
+            "type": "string"
+          },
+          "window_start": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "id",
+          "bank_a",
+          "bank_b",
+          "window_start",
+          "window_end",
+          "positions",
+          "transfer_ids",
+          "status",
+          "confirmed_by",
+          "created_at"
+        ],
+        "additionalProperties": false
+      },
+      "StandingOrder": {
+        "$id": "StandingOrder",
+        "properties": {
+          "amount": {
+            "type": "string"
+          },
+          "bank_id": {
+            "type": "string"
+          },
+          "created_at": {
+            "type": "string"
+          },
+          "created_by": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "id": {
+            "type": "string"
+          },
+          "next_run_date": {
+            "type": "string"
+          },
+          "run_count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "schedule": {
+            "type": "string"
+          },
+          "start_date": {
+            "type": "string"
+          },
+          "status": {
+            "type": "string"
+          },
+          "updated_at": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "id",
+          "user_id",
+          "amount",
+          "currency",
+          "bank_id",
+          "schedule",
+          "start_date",
+          "next_run_date",
+          "run_count",
+          "status",
+          "created_by",
+          "created_at",
+          "updated_at"
+        ],
+        "additionalProperties": false
+      },
+      "StandingOrderExecution": {
+        "$id": "StandingOrderExecution",
+        "properties": {
+          "as_of": {
+            "type": "string"
+          },
+          "failed": {
+            "type": "array",
+            "items": {
+              "$ref": "StandingOrderFailure"
+            }
+          },
+          "pending": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "transactions": {
+            "type": "array",
+            "items": {
+              "$ref": "TransactionRecord"
+            }
+          }
+        },
+        "required": [
+          "as_of",
+          "transactions",
+          "failed",
+          "pending"
+        ],
+        "additionalProperties": false
+      },
+      "StandingOrderFailure": {
+        "$id": "StandingOrderFailure",
+        "properties": {
+          "error": {
+            "type": "string"
+          },
+          "order_id": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "order_id",
+          "user_id",
+          "error"
+        ],
+        "additionalProperties": false
+      },
+      "Statement": {
+        "$id": "Statement",
+        "properties": {
+          "banks": {
+            "type": "array",
+            "items": {
+              "$ref": "BankStatement"
+            }
+          },
+          "categories": {
+            "type": "array",
+            "items": {
+              "$ref": "CategoryStatement"
+            }
+          },
+          "closing_count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "month": {
+            "type": "string"
+          },
+          "opening_count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "totals": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            }
+          },
+          "transactions": {
+            "type": "array",
+            "items": {
+              "$ref": "Transaction"
+            }
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "user_id",
+          "month",
+          "opening_count",
+          "closing_count",
+          "totals",
+          "banks",
+          "categories",
+          "transactions"
+        ],
+        "additionalProperties": false
+      },
+      "TokenAccount": {
+        "$id": "TokenAccount",
+        "properties": {
+          "account_id": {
+            "type": "string"
+          },
+          "linked_at": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "user_id",
+          "account_id",
+          "linked_at"
+        ],
+        "additionalProperties": false
+      },
+      "TokenTransfer": {
+        "$id": "TokenTransfer",
+        "properties": {
+          "chaincode": {
+            "type": "string"
+          },
+          "from": {
+            "type": "string"
+          },
+          "to": {
+            "type": "string"
+          },
+          "tx_id": {
+            "type": "string"
+          },
+          "value": {
+            "type": "integer",
+            "format": "int64"
+          }
+        },
+        "required": [
+          "chaincode",
+          "from",
+          "to",
+          "value",
+          "tx_id"
+        ],
+        "additionalProperties": false
+      },
+      "Transaction": {
+        "$id": "Transaction",
+        "properties": {
+          "amount": {
+            "type": "string"
+          },
+          "bank_id": {
+            "type": "string"
+          },
+          "category": {
+            "type": "string"
+          },
+          "created_at": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "date": {
+            "type": "string"
+          },
+          "hash": {
+            "type": "string"
+          },
+          "reference": {
+            "type": "string"
+          },
+          "signature": {
+            "type": "string"
+          },
+          "standing_order_id": {
+            "type": "string"
+          },
+          "standing_order_run": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "tags": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "token_transfer": {
+            "$ref": "TokenTransfer"
+          },
+          "updated_at": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "hash",
+          "amount",
+          "currency",
+          "date",
+          "bank_id"
+        ],
+        "additionalProperties": false
+      },
+      "TransactionPage": {
+        "$id": "TransactionPage",
+        "properties": {
+          "bookmark": {
+            "type": "string"
+          },
+          "transactions": {
+            "type": "array",
+            "items": {
+              "$ref": "TransactionRecord"
+            }
+          }
+        },
+        "required": [
+          "transactions",
+          "bookmark"
+        ],
+        "additionalProperties": false
+      },
+      "TransactionProof": {
+        "$id": "TransactionProof",
+        "properties": {
+          "hash": {
+            "type": "string"
+          },
+          "proof": {
+            "$ref": "Proof"
+          },
+          "root": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "user_id",
+          "hash",
+          "root",
+          "proof"
+        ],
+        "additionalProperties": false
+      },
+      "TransactionRecord": {
+        "$id": "TransactionRecord",
+        "properties": {
+          "amount": {
+            "type": "string"
+          },
+          "bank_id": {
+            "type": "string"
+          },
+          "category": {
+            "type": "string"
+          },
+          "created_at": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "date": {
+            "type": "string"
+          },
+          "hash": {
+            "type": "string"
+          },
+          "reference": {
+            "type": "string"
+          },
+          "signature": {
+            "type": "string"
+          },
+          "standing_order_id": {
+            "type": "string"
+          },
+          "standing_order_run": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "tags": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "token_transfer": {
+            "$ref": "TokenTransfer"
+          },
+          "updated_at": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "user_id",
+          "hash",
+          "amount",
+          "currency",
+          "date",
+          "bank_id"
+        ],
+        "additionalProperties": false
+      },
+      "Transfer": {
+        "$id": "Transfer",
+        "properties": {
+          "amount": {
+            "type": "string"
+          },
+          "bank_id": {
+            "type": "string"
+          },
+          "created_at": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "from_user_id": {
+            "type": "string"
+          },
+          "id": {
+            "type": "string"
+          },
+          "reference": {
+            "type": "string"
+          },
+          "settlement_batch_id": {
+            "type": "string"
+          },
+          "to_bank_id": {
+            "type": "string"
+          },
+          "to_user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "id",
+          "from_user_id",
+          "to_user_id",
+          "amount",
+          "currency",
+          "bank_id",
+          "to_bank_id",
+          "created_at"
+        ],
+        "additionalProperties": false
+      },
+      "TransferEntry": {
+        "$id": "TransferEntry",
+        "properties": {
+          "amount": {
+            "type": "string"
+          },
+          "balance": {
+            "type": "string"
+          },
+          "bank_id": {
+            "type": "string"
+          },
+          "counterparty": {
+            "type": "string"
+          },
+          "created_at": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "direction": {
+            "type": "string"
+          },
+          "reference": {
+            "type": "string"
+          },
+          "transfer_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "transfer_id",
+          "direction",
+          "counterparty",
+          "amount",
+          "currency",
+          "bank_id",
+          "balance",
+          "created_at"
+        ],
+        "additionalProperties": false
+      },
+      "User": {
+        "$id": "User",
+        "properties": {
+          "archives": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "balances": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            }
+          },
+          "created_at": {
+            "type": "string"
+          },
+          "erased_at": {
+            "type": "string"
+          },
+          "id": {
+            "type": "string"
+          },
+          "transactions": {
+            "type": "array",
+            "items": {
+              "$ref": "Transaction"
+            }
+          },
+          "transfers": {
+            "type": "array",
+            "items": {
+              "$ref": "TransferEntry"
+            }
+          },
+          "updated_at": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "id"
+        ],
+        "additionalProperties": false
+      },
+      "UserDataExport": {
+        "$id": "UserDataExport",
+        "properties": {
+          "bundle": {
+            "type": "string"
+          },
+          "digest": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "bundle",
+          "digest"
+        ],
+        "additionalProperties": false
+      },
+      "UserMerkleTree": {
+        "$id": "UserMerkleTree",
+        "properties": {
+          "frontier": {
+            "$ref": "Frontier"
+          },
+          "root": {
+            "type": "string"
+          },
+          "updated_at": {
+            "type": "string"
+          },
+          "user_id": {
+            "type": "string"
+          }
+        },
+        "required": [
+          "user_id",
+          "root",
+          "frontier",
+          "updated_at"
+        ],
+        "additionalProperties": false
+      },
+      "UserPage": {
+        "$id": "UserPage",
+        "properties": {
+          "bookmark": {
+            "type": "string"
+          },
+          "users": {
+            "type": "array",
+            "items": {
+              "$ref": "User"
+            }
+          }
+        },
+        "required": [
+          "users",
+          "bookmark"
+        ],
+        "additionalProperties": false
+      },
+      "WatchlistEntry": {
+        "$id": "WatchlistEntry",
+        "properties": {
+          "action": {
+            "type": "string"
+          },
+          "added_at": {
+            "type": "string"
+          },
+          "id": {
+            "type": "string"
+          },
+          "identifiers": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "name": {
+            "type": "string"
+          },
+          "tokens": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          }
+        },
+        "required": [
+          "id",
+          "name",
+          "tokens",
+          "identifiers",
+          "action",
+          "added_at"
+        ],
+        "additionalProperties": false
+      }
+    }
+  }
+}
+`
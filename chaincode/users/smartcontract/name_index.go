@@ -0,0 +1,127 @@
+package smartcontract
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// nameIndexPrefix starts the keys name~normalized~id of the user name index, kept in
+// LookupCollection since the normalized name is personal data. They are simple keys rather
+// than composite keys because Fabric only range queries simple keys, and a prefix search
+// must range over the last characters of the name. normalizeName turns "~" into a space so
+// the separator is unambiguous. Values are a single 0x00 byte
+const nameIndexPrefix = "name~"
+
+// UserPage one page of a user query. Bookmark is passed back to get the next page and is
+// empty once the query is exhausted. Name searches bookmark the ID of the last user rather
+// than its index key, which holds the user's name
+type UserPage struct {
+	Users    []*User `json:"users"`
+	Bookmark string  `json:"bookmark"`
+}
+
+// nameIndexKey returns the name index key of user userId named name, empty when the name
+// has no letters and so cannot be searched
+func nameIndexKey(userId string, name string) string {
+	normalized := normalizeName(name)
+	if normalized == "" {
+		return ""
+	}
+	return nameIndexPrefix + normalized + "~" + userId
+}
+
+// updateNameIndex replaces the name index entry oldKey by the entry key, either empty for
+// none
+func updateNameIndex(ctx TransactionContextInterface, oldKey string, key string) error {
+	if oldKey == key {
+		return nil
+	}
+	if oldKey != "" {
+		_, err := removePrivateData(ctx, LookupCollection, oldKey)
+		if err != nil {
+			return err
+		}
+	}
+	if key == "" {
+		return nil
+	}
+	err := ctx.GetStub().PutPrivateData(LookupCollection, key, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put private data: %v", err)
+	}
+	return nil
+}
+
+// removeNameIndex removes the name index entry of userId, found through its ScreeningData
+func removeNameIndex(ctx TransactionContextInterface, userId string) error {
+	data, err := lookupScreeningData(ctx, userId)
+	if err != nil || data == nil {
+		return err
+	}
+	return updateNameIndex(ctx, data.IndexKey, "")
+}
+
+// SearchUsersByNamePrefix returns the users whose normalized name starts with the
+// normalized prefix, pageSize at a time, ordered by name then ID. Full-width and
+// half-width forms, accents and case are folded, so "ＷＡＮＧ" finds "Wang Xiaoming". Users
+// who gave the caller no consent are skipped. The index is only held by the peers of
+// LookupCollection
+func (s *SmartContract) SearchUsersByNamePrefix(ctx TransactionContextInterface, prefix string, pageSize int, bookmark string) (*UserPage, error) {
+	normalized := normalizeName(prefix)
+	if normalized == "" {
+		return nil, fmt.Errorf("name prefix %q has no letters", prefix)
+	}
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive")
+	}
+	startKey := nameIndexPrefix + normalized
+	endKey := startKey + string(utf8.MaxRune)
+	bookmarkKey := ""
+	if bookmark != "" {
+		data, err := lookupScreeningData(ctx, bookmark)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil || !strings.HasPrefix(data.IndexKey, startKey) {
+			return nil, fmt.Errorf("invalid bookmark")
+		}
+		bookmarkKey = data.IndexKey
+		startKey = bookmarkKey
+	}
+
+	resultsIterator, err := ctx.GetStub().GetPrivateDataByRange(LookupCollection, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	page := UserPage{Users: []*User{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if queryResponse.Key == bookmarkKey {
+			continue
+		}
+		id := queryResponse.Key[strings.LastIndex(queryResponse.Key, "~")+1:]
+		allowed, err := s.hasConsent(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+		user, err := s.readUser(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		page.Users = append(page.Users, user)
+		if len(page.Users) == pageSize {
+			page.Bookmark = id
+			return &page, nil
+		}
+	}
+	return &page, nil
+}
@@ -0,0 +1,53 @@
+package smartcontract
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizeName folds a personal name for comparison: full-width forms become half-width,
+// accents are dropped, Latin letters are lower cased and punctuation becomes a space, so
+// "ＷＡＮＧ, Xiǎo-Míng" and "wang xiao ming" normalize alike
+func normalizeName(name string) string {
+	var folded strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		folded.WriteRune(r)
+	}
+	name = strings.ToLower(norm.NFKC.String(folded.String()))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// nameTokens splits a normalized name into tokens. Latin words are one token each while
+// every Han character is a token of its own, as Chinese names are not space separated
+func nameTokens(normalized string) []string {
+	var tokens []string
+	for _, word := range strings.Fields(normalized) {
+		var latin []rune
+		for _, r := range word {
+			if !unicode.Is(unicode.Han, r) {
+				latin = append(latin, r)
+				continue
+			}
+			if len(latin) > 0 {
+				tokens = append(tokens, string(latin))
+				latin = nil
+			}
+			tokens = append(tokens, string(r))
+		}
+		if len(latin) > 0 {
+			tokens = append(tokens, string(latin))
+		}
+	}
+	return tokens
+}
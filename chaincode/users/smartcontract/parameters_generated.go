@@ -0,0 +1,61 @@
+// Code generated by metadatagen from the transaction function signatures. DO NOT EDIT.
+
+package smartcontract
+
+// parameterFields binds the parameters of a transaction, in order, to the struct field
+// whose rule they must satisfy, see parameterNames. Parameters bound to "" are not checked
+var parameterFields = map[string][]string{
+	"AddUserMSP":                  {"Consent.MSPID"},
+	"AddWatchlistEntry":           {"WatchlistEntry.ID", "WatchlistEntry.Name", "", "WatchlistEntry.Action"},
+	"ArchiveTransactions":         {"User.ID", "Transaction.Date"},
+	"CategorizeTransaction":       {"Transaction.Hash", ""},
+	"ConfirmSettlementBatch":      {"Bank.ID", "", ""},
+	"CreateStandingOrder":         {"User.ID", "Transaction.Amount", "Transaction.Currency", "Bank.ID", "StandingOrder.Schedule"},
+	"CreateTransaction":           {"User.ID", "Transaction.Hash", "Transaction.Amount", "Transaction.Currency", "Transaction.Date", "Bank.ID", "Transaction.Reference", ""},
+	"CreateUser":                  {"User.ID"},
+	"DeleteUser":                  {"User.ID"},
+	"EraseUserPersonalData":       {"User.ID"},
+	"ExecuteDueStandingOrders":    {"Transaction.Date"},
+	"ExportUserData":              {"User.ID"},
+	"GetAMLRule":                  {"Transaction.Currency"},
+	"GetBankByID":                 {"Bank.ID"},
+	"GetDispute":                  {"Transaction.Hash"},
+	"GetErasureCertificate":       {"User.ID"},
+	"GetTokenAccount":             {"User.ID"},
+	"GetTransactionProof":         {"User.ID", "Transaction.Hash"},
+	"GetUser":                     {"User.ID"},
+	"GetUserBalances":             {"User.ID"},
+	"GetUserByTransactionHash":    {"Transaction.Hash"},
+	"GetUserMerkleTree":           {"User.ID"},
+	"GetUserPersonalData":         {"User.ID"},
+	"GetUserStatement":            {"User.ID", ""},
+	"GetUserTokenBalance":         {"User.ID"},
+	"GrantConsent":                {"User.ID", "Consent.MSPID", "Consent.ExpiresOn"},
+	"InterbankTransfer":           {"User.ID", "User.ID", "Transaction.Amount", "Transaction.Currency", "Bank.ID", "Bank.ID", "Transfer.Reference"},
+	"LinkTokenAccount":            {"User.ID"},
+	"ListMyBankTransactions":      {"Transaction.Date", "Transaction.Date", "", "", ""},
+	"ListTransactionsByBank":      {"Bank.ID", "Transaction.Date", "Transaction.Date", "", "", ""},
+	"ListTransactionsByDateRange": {"Transaction.Date", "Transaction.Date", "", "", ""},
+	"ListTransactionsByTag":       {"User.ID", ""},
+	"ListUserConsents":            {"User.ID"},
+	"ListUserTransactions":        {"User.ID"},
+	"OpenDispute":                 {"Transaction.Hash", "Dispute.Reason"},
+	"ProposeSettlementBatches":    {"Transaction.Date", "Transaction.Date"},
+	"RegisterBankKey":             {"Bank.ID", ""},
+	"RemoveUserMSP":               {"Consent.MSPID"},
+	"RemoveWatchlistEntry":        {"WatchlistEntry.ID"},
+	"ResolveDispute":              {"Transaction.Hash", "Dispute.Outcome", "DisputeEvent.Note"},
+	"RespondDispute":              {"Transaction.Hash", "DisputeEvent.Note"},
+	"RevokeConsent":               {"User.ID", "Consent.MSPID"},
+	"RotateBankKey":               {"Bank.ID", "", ""},
+	"ScreenName":                  {"WatchlistEntry.Name"},
+	"SearchUsersByNamePrefix":     {"PersonalData.Name", "", ""},
+	"SetAMLRule":                  {"Transaction.Currency", "", ""},
+	"SetBankMSPID":                {"Bank.ID", "Consent.MSPID"},
+	"TagTransaction":              {"Transaction.Hash", ""},
+	"TransferBetweenUsers":        {"User.ID", "User.ID", "Transaction.Amount", "Transaction.Currency", "Bank.ID", "Transfer.Reference"},
+	"UpdateUser":                  {"User.ID"},
+	"UserExists":                  {"User.ID"},
+	"VerifyArchivedTransaction":   {"Transaction.Hash", ""},
+	"VerifyTransaction":           {"Transaction.Hash"},
+}
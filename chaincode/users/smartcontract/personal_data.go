@@ -0,0 +1,115 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PersonalDataCollection private data collection, see collections_config.json, holding the
+// PersonalData of users under their ID
+const PersonalDataCollection = "userPersonalData"
+
+// PersonalDataCollectionMSPID the member organization of PersonalDataCollection. The
+// collection is member only read, so clients of other MSPs cannot read it
+const PersonalDataCollectionMSPID = "Org1MSP"
+
+// PersonalDataTransientKey transient map entry carrying the PersonalData of CreateUser and
+// UpdateUser as JSON, so that it never reaches the public ledger, not even as a transaction
+// argument
+const PersonalDataTransientKey = "personal_data"
+
+// PersonalData identifying details of a user kept off the public ledger. Name and Email are
+// required, the other details optional
+type PersonalData struct {
+	Name            string   `json:"name" minLength:"1" maxLength:"64"`
+	Email           string   `json:"email" maxLength:"254" format:"email"`
+	FullName        string   `json:"full_name,omitempty" metadata:",optional"`
+	NationalID      string   `json:"national_id,omitempty" metadata:",optional"`
+	Phone           string   `json:"phone,omitempty" metadata:",optional"`
+	Address         string   `json:"address,omitempty" metadata:",optional"`
+	BirthDate       string   `json:"birth_date,omitempty" metadata:",optional"`
+	EncryptedFields []string `json:"encrypted_fields,omitempty" metadata:",optional"` // fields stored encrypted, see EncryptionKeyTransientKey
+}
+
+// transientPersonalData returns the PersonalData supplied in the transient map, checked
+// against the rules of its fields
+func transientPersonalData(ctx TransactionContextInterface) (*PersonalData, error) {
+	var personalData PersonalData
+	found, err := ctx.GetTransientJSON(PersonalDataTransientKey, &personalData)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("the personal data of the user must be supplied in the transient map under %s", PersonalDataTransientKey)
+	}
+	checks := []struct{ field, value string }{
+		{"PersonalData.Name", personalData.Name},
+		{"PersonalData.Email", personalData.Email},
+	}
+	for _, check := range checks {
+		err = validationRules[check.field].check(check.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid personal data: %v", err)
+		}
+	}
+	personalData.EncryptedFields = nil
+	return &personalData, nil
+}
+
+// storePersonalData writes the PersonalData of userId to PersonalDataCollection, encrypted
+// when a key is supplied, see encryptPersonalData
+func storePersonalData(ctx TransactionContextInterface, userId string, personalData *PersonalData) error {
+	err := encryptPersonalData(ctx, userId, personalData)
+	if err != nil {
+		return err
+	}
+	personalDataJson, err := json.Marshal(personalData)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutPrivateData(PersonalDataCollection, userId, personalDataJson)
+	if err != nil {
+		return fmt.Errorf("failed to put private data: %v", err)
+	}
+	return nil
+}
+
+// readPersonalData returns the PersonalData of userId, nil when it has none. Encrypted
+// fields are decrypted when the key is supplied, see decryptPersonalData
+func readPersonalData(ctx TransactionContextInterface, userId string) (*PersonalData, error) {
+	personalDataJson, err := ctx.GetStub().GetPrivateData(PersonalDataCollection, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private data: %v", err)
+	}
+	if personalDataJson == nil {
+		return nil, nil
+	}
+	var personalData PersonalData
+	err = json.Unmarshal(personalDataJson, &personalData)
+	if err != nil {
+		return nil, err
+	}
+	err = decryptPersonalData(ctx, userId, &personalData)
+	if err != nil {
+		return nil, err
+	}
+	return &personalData, nil
+}
+
+// GetUserPersonalData returns the private PersonalData of a user, decrypted when the key is
+// supplied under DecryptionKeyTransientKey. Banks need the user's consent and their peer
+// must be a member of PersonalDataCollection
+func (s *SmartContract) GetUserPersonalData(ctx TransactionContextInterface, id string) (*PersonalData, error) {
+	err := s.requireConsent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	personalData, err := readPersonalData(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if personalData == nil {
+		return nil, fmt.Errorf("the user %s has no personal data", id)
+	}
+	return personalData, nil
+}
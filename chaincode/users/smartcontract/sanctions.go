@@ -0,0 +1,379 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const WatchlistPrefix = "Watchlist_"
+
+const (
+	WatchlistBlock = "block"
+	WatchlistFlag  = "flag"
+
+	AlertRuleSanctions = "sanctions"
+)
+
+// SanctionsMatchThreshold minimum similarity, from 0 to 1, for a name to match a
+// watchlist entry
+const SanctionsMatchThreshold = 0.85
+
+// WatchlistEntry sanctioned party. Action tells whether a match blocks the operation or
+// only raises an alert
+type WatchlistEntry struct {
+	ID          string   `json:"id" pattern:"^[0-9A-Za-z_-]+$" maxLength:"64"`
+	Name        string   `json:"name" minLength:"1" maxLength:"256"`
+	Tokens      []string `json:"tokens"`
+	Identifiers []string `json:"identifiers"`
+	Action      string   `json:"action" pattern:"^(block|flag)$"`
+	AddedAt     string   `json:"added_at"`
+}
+
+// SanctionsMatch watchlist entry matched by a screened name or identifier
+type SanctionsMatch struct {
+	EntryID string  `json:"entry_id"`
+	Name    string  `json:"name"`
+	Action  string  `json:"action"`
+	Score   float64 `json:"score"` // 1 for an identifier match
+}
+
+// LookupCollection private data collection, see collections_config.json, holding what
+// the chaincode screens users by. Unlike PersonalDataCollection it is not member only read:
+// the chaincode reads it on behalf of callers of any organization but never returns it
+const LookupCollection = "userLookup"
+
+// ScreeningData normalized name tokens and identifiers of a user, kept in LookupCollection
+// under the user ID so the user can be screened when transacting whether or not its
+// PersonalData is encrypted, with the key of its entry in the name index. Nothing of it
+// reaches the public ledger, where even salted digests could be matched against a list of
+// common names
+type ScreeningData struct {
+	Tokens      []string `json:"tokens"`
+	Identifiers []string `json:"identifiers"`
+	IndexKey    string   `json:"index_key,omitempty" metadata:",optional"`
+}
+
+// newScreeningData returns the ScreeningData of user userId, whose PersonalData is in clear
+func newScreeningData(userId string, personalData *PersonalData) *ScreeningData {
+	data := &ScreeningData{
+		Tokens:      nameTokens(normalizeName(personalData.Name)),
+		Identifiers: []string{},
+		IndexKey:    nameIndexKey(userId, personalData.Name),
+	}
+	for _, identifier := range []string{userId, personalData.Email} {
+		if identifier = normalizeIdentifier(identifier); identifier != "" {
+			data.Identifiers = append(data.Identifiers, identifier)
+		}
+	}
+	return data
+}
+
+// storeScreeningData writes the ScreeningData of userId to LookupCollection
+func storeScreeningData(ctx TransactionContextInterface, userId string, data *ScreeningData) error {
+	dataJson, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutPrivateData(LookupCollection, userId, dataJson)
+	if err != nil {
+		return fmt.Errorf("failed to put private data: %v", err)
+	}
+	return nil
+}
+
+// lookupScreeningData returns the ScreeningData of userId, nil when this peer holds none
+func lookupScreeningData(ctx TransactionContextInterface, userId string) (*ScreeningData, error) {
+	dataJson, err := ctx.GetStub().GetPrivateData(LookupCollection, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private data: %v", err)
+	}
+	if dataJson == nil {
+		return nil, nil
+	}
+	var data ScreeningData
+	err = json.Unmarshal(dataJson, &data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// readScreeningData returns the ScreeningData of userId. Peers outside LookupCollection
+// do not hold it, so a user cannot be screened on them
+func readScreeningData(ctx TransactionContextInterface, userId string) (*ScreeningData, error) {
+	data, err := lookupScreeningData(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("no screening data of user %s on this peer, it must be a member of %s", userId, LookupCollection)
+	}
+	return data, nil
+}
+
+// normalizeIdentifier folds passport, ID card and similar numbers for comparison
+func normalizeIdentifier(identifier string) string {
+	return strings.ReplaceAll(strings.ToUpper(normalizeName(identifier)), " ", "")
+}
+
+// levenshtein returns the edit distance between a and b, counted in runes
+func levenshtein(a string, b string) int {
+	x, y := []rune(a), []rune(b)
+	previous := make([]int, len(y)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		current := make([]int, len(y)+1)
+		current[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(y)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// tokensMatch tolerates one typo in a Latin token of 4 runes or more and two from 8 runes.
+// Han characters must be equal
+func tokensMatch(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	length := len([]rune(a))
+	if other := len([]rune(b)); other < length {
+		length = other
+	}
+	switch {
+	case length >= 8:
+		return levenshtein(a, b) <= 2
+	case length >= 4:
+		return levenshtein(a, b) <= 1
+	}
+	return false
+}
+
+// nameSimilarity scores two token lists from 0 to 1. It takes the better of the share of
+// tokens matching in any order and the edit similarity of the names written without
+// spaces, so "wang xiao ming" still matches "wang xiaoming"
+func nameSimilarity(a []string, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	used := make([]bool, len(b))
+	matched := 0
+	for _, token := range a {
+		for j, other := range b {
+			if !used[j] && tokensMatch(token, other) {
+				used[j] = true
+				matched++
+				break
+			}
+		}
+	}
+	score := 2 * float64(matched) / float64(len(a)+len(b))
+
+	x, y := strings.Join(a, ""), strings.Join(b, "")
+	longest := len([]rune(x))
+	if other := len([]rune(y)); other > longest {
+		longest = other
+	}
+	if joined := 1 - float64(levenshtein(x, y))/float64(longest); joined > score {
+		score = joined
+	}
+	return math.Round(score*100) / 100
+}
+
+// AddWatchlistEntry adds or replaces a sanctioned party. identifiers is a comma separated
+// list of document numbers, action WatchlistBlock or WatchlistFlag. Admin only
+func (s *SmartContract) AddWatchlistEntry(ctx TransactionContextInterface, id string, name string, identifiers string, action string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if action != WatchlistBlock && action != WatchlistFlag {
+		return fmt.Errorf("unknown watchlist action %q, expected %s or %s", action, WatchlistBlock, WatchlistFlag)
+	}
+	tokens := nameTokens(normalizeName(name))
+	if len(tokens) == 0 {
+		return fmt.Errorf("watchlist name %q has no letters", name)
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+
+	entry := WatchlistEntry{
+		ID:          id,
+		Name:        name,
+		Tokens:      tokens,
+		Identifiers: []string{},
+		Action:      action,
+		AddedAt:     now,
+	}
+	for _, identifier := range strings.Split(identifiers, ",") {
+		if identifier = normalizeIdentifier(identifier); identifier != "" {
+			entry.Identifiers = append(entry.Identifiers, identifier)
+		}
+	}
+	return ctx.PutStateJSON(WatchlistPrefix+id, entry)
+}
+
+// RemoveWatchlistEntry deletes a sanctioned party. Admin only
+func (s *SmartContract) RemoveWatchlistEntry(ctx TransactionContextInterface, id string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	exists, err := ctx.GetStateJSON(WatchlistPrefix+id, &WatchlistEntry{})
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the watchlist entry %s does not exist", id)
+	}
+	return ctx.GetStub().DelState(WatchlistPrefix + id)
+}
+
+// ListWatchlist returns every watchlist entry ordered by ID. Admin only
+func (s *SmartContract) ListWatchlist(ctx TransactionContextInterface) ([]*WatchlistEntry, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.watchlist(ctx)
+}
+
+func (s *SmartContract) watchlist(ctx TransactionContextInterface) ([]*WatchlistEntry, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(WatchlistPrefix, prefixRangeEnd(WatchlistPrefix))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	entries := []*WatchlistEntry{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var entry WatchlistEntry
+		err = json.Unmarshal(queryResponse.Value, &entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+// screenParty matches name and identifiers against the watchlist
+func (s *SmartContract) screenParty(ctx TransactionContextInterface, name string, identifiers []string) ([]*SanctionsMatch, error) {
+	data := &ScreeningData{Tokens: nameTokens(normalizeName(name))}
+	for _, identifier := range identifiers {
+		data.Identifiers = append(data.Identifiers, normalizeIdentifier(identifier))
+	}
+	return s.screenData(ctx, data)
+}
+
+// screenData matches the normalized tokens and identifiers of data against the watchlist
+func (s *SmartContract) screenData(ctx TransactionContextInterface, data *ScreeningData) ([]*SanctionsMatch, error) {
+	return s.matchWatchlist(ctx, func(entry *WatchlistEntry) float64 {
+		for _, identifier := range data.Identifiers {
+			for _, listed := range entry.Identifiers {
+				if identifier == listed {
+					return 1
+				}
+			}
+		}
+		return nameSimilarity(data.Tokens, entry.Tokens)
+	})
+}
+
+// matchWatchlist returns the watchlist entries score gives at least SanctionsMatchThreshold
+func (s *SmartContract) matchWatchlist(ctx TransactionContextInterface, score func(entry *WatchlistEntry) float64) ([]*SanctionsMatch, error) {
+	entries, err := s.watchlist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matches := []*SanctionsMatch{}
+	for _, entry := range entries {
+		if entryScore := score(entry); entryScore >= SanctionsMatchThreshold {
+			matches = append(matches, &SanctionsMatch{EntryID: entry.ID, Name: entry.Name, Action: entry.Action, Score: entryScore})
+		}
+	}
+	return matches, nil
+}
+
+// ScreenName returns the watchlist entries name matches
+func (s *SmartContract) ScreenName(ctx TransactionContextInterface, name string) ([]*SanctionsMatch, error) {
+	return s.screenParty(ctx, name, nil)
+}
+
+// screenUser screens a user being created, updated or transacting for by its ScreeningData,
+// read from LookupCollection when data is nil. A match with a WatchlistBlock entry fails
+// the operation, WatchlistFlag matches are stored as alerts under subject, the transaction
+// hash or the user ID and Fabric transaction ID. Alerts are public, so they do not quote
+// the user's name
+func (s *SmartContract) screenUser(ctx TransactionContextInterface, user *User, data *ScreeningData, subject string, transaction *Transaction) error {
+	if data == nil {
+		var err error
+		data, err = readScreeningData(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+	}
+	matches, err := s.screenData(ctx, data)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if match.Action == WatchlistBlock {
+			return fmt.Errorf("the user %s matches watchlist entry %s", user.ID, match.EntryID)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		alert := Alert{
+			ID:        subject + "_" + AlertRuleSanctions + "_" + match.EntryID,
+			UserId:    user.ID,
+			Rule:      AlertRuleSanctions,
+			Status:    AlertStatusOpen,
+			CreatedAt: now,
+			Detail:    fmt.Sprintf("screening data matches watchlist entry %s %q with score %.2f", match.EntryID, match.Name, match.Score),
+		}
+		if transaction != nil {
+			alert.TransactionHash = transaction.Hash
+			alert.Currency = transaction.Currency
+			alert.Amount = transaction.Amount
+		}
+		err = ctx.PutStateJSON(AlertPrefix+alert.ID, alert)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package smartcontract
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TokenChaincodeName is the token-erc-20 chaincode settlements are sent to. The client
+// cannot choose another one, any chaincode answering TransferFrom with success would
+// otherwise get the transaction recorded as settled
+const TokenChaincodeName = "token-erc-20"
+
+// SettlementTransientKey is the transient map entry that switches CreateTransaction to
+// settlement mode. Its value is a JSON encoded SettlementRequest
+const SettlementTransientKey = "settlement"
+
+// SettlementRequest asks CreateTransaction to move the transaction amount between two
+// token accounts. The submitting client must hold an allowance on From
+type SettlementRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TokenTransfer records the token transfer which settled a Transaction
+type TokenTransfer struct {
+	Chaincode string `json:"chaincode"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Value     int    `json:"value"`
+	TxID      string `json:"tx_id"`
+}
+
+// settleTransaction calls TransferFrom on the token chaincode of the same channel when a
+// SettlementRequest was supplied in the transient map. It returns nil when no settlement
+// was requested and an error, failing the whole transaction, when the transfer is rejected
+func (s *SmartContract) settleTransaction(ctx TransactionContextInterface, transaction Transaction) (*TokenTransfer, error) {
+	var request SettlementRequest
+	requested, err := ctx.GetTransientJSON(SettlementTransientKey, &request)
+	if err != nil || !requested {
+		return nil, err
+	}
+	if request.From == "" || request.To == "" {
+		return nil, fmt.Errorf("settlement requires both from and to token accounts")
+	}
+
+	amount, err := parseAmount(transaction.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if !amount.IsInt() || !amount.Num().IsInt64() {
+		return nil, fmt.Errorf("amount %s cannot be settled in whole tokens", transaction.Amount)
+	}
+	value := int(amount.Num().Int64())
+	if value <= 0 {
+		return nil, fmt.Errorf("amount %s must be positive to be settled", transaction.Amount)
+	}
+
+	args := [][]byte{
+		[]byte("TransferFrom"),
+		[]byte(request.From),
+		[]byte(request.To),
+		[]byte(strconv.Itoa(value)),
+	}
+	response := ctx.GetStub().InvokeChaincode(TokenChaincodeName, args, "")
+	if response.Status >= 400 {
+		return nil, fmt.Errorf("token transfer for transaction %s was rejected: %s", transaction.Hash, response.Message)
+	}
+
+	return &TokenTransfer{
+		Chaincode: TokenChaincodeName,
+		From:      request.From,
+		To:        request.To,
+		Value:     value,
+		TxID:      ctx.GetStub().GetTxID(),
+	}, nil
+}
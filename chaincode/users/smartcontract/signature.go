@@ -0,0 +1,149 @@
+package smartcontract
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+// parseBankPublicKey parses a PEM encoded PKIX ECDSA public key
+func parseBankPublicKey(publicKey string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an ECDSA key")
+	}
+	return ecdsaKey, nil
+}
+
+// verifySignature checks a base64 encoded ASN.1 ECDSA signature over the SHA-256 of payload
+func verifySignature(publicKey string, payload []byte, signature string) error {
+	key, err := parseBankPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	signatureDer, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("signature is not base64 encoded: %v", err)
+	}
+	digest := sha256.Sum256(payload)
+	if !ecdsa.VerifyASN1(key, digest[:], signatureDer) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+// checkBankSignature verifies that signature was made by the registered key of the bank
+// named in transaction over its CanonicalTransaction
+func checkBankSignature(bank *Bank, userId string, transaction Transaction, signature string) error {
+	if bank.PublicKey == "" {
+		return fmt.Errorf("the bank %s has no registered public key", bank.ID)
+	}
+	payload, err := CanonicalTransaction(userId, transaction)
+	if err != nil {
+		return err
+	}
+	err = verifySignature(bank.PublicKey, payload, signature)
+	if err != nil {
+		return fmt.Errorf("transaction %s is not signed by bank %s: %v", transaction.Hash, bank.ID, err)
+	}
+	return nil
+}
+
+// RegisterBankKey sets the first public key of a bank. Admin only, later changes go
+// through RotateBankKey
+func (s *SmartContract) RegisterBankKey(ctx TransactionContextInterface, bankId string, publicKey string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	bank, err := s.GetBankByID(ctx, bankId)
+	if err != nil {
+		return err
+	}
+	if bank.PublicKey != "" {
+		return fmt.Errorf("the bank %s already has a public key, use RotateBankKey", bankId)
+	}
+	_, err = parseBankPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	bank.PublicKey = publicKey
+	bank.KeyVersion = 1
+	bank.KeyUpdatedAt = now.Format(time.RFC3339)
+	bank.UpdatedAt = bank.KeyUpdatedAt
+
+	return ctx.PutStateJSON(BankPrefix+bankId, bank)
+}
+
+// keyRotation is the payload a bank signs to rotate its key. Binding the bank and the
+// version the new key gets keeps a signed rotation from being replayed for another bank
+// or to bring back a retired key
+type keyRotation struct {
+	BankId     string `json:"bank_id"`
+	KeyVersion int    `json:"key_version"`
+	PublicKey  string `json:"public_key"`
+}
+
+// KeyRotationPayload returns the canonical JSON a bank signs with its current key to make
+// publicKey its key version keyVersion
+func KeyRotationPayload(bankId string, keyVersion int, publicKey string) ([]byte, error) {
+	return json.Marshal(keyRotation{
+		BankId:     bankId,
+		KeyVersion: keyVersion,
+		PublicKey:  publicKey,
+	})
+}
+
+// RotateBankKey replaces the public key of a bank. signature must be made with the current
+// key over KeyRotationPayload for the next key version, proving the bank itself asked for
+// the rotation
+func (s *SmartContract) RotateBankKey(ctx TransactionContextInterface, bankId string, newPublicKey string, signature string) error {
+	bank, err := s.GetBankByID(ctx, bankId)
+	if err != nil {
+		return err
+	}
+	if bank.PublicKey == "" {
+		return fmt.Errorf("the bank %s has no registered public key", bankId)
+	}
+	_, err = parseBankPublicKey(newPublicKey)
+	if err != nil {
+		return err
+	}
+	payload, err := KeyRotationPayload(bankId, bank.KeyVersion+1, newPublicKey)
+	if err != nil {
+		return err
+	}
+	err = verifySignature(bank.PublicKey, payload, signature)
+	if err != nil {
+		return fmt.Errorf("key rotation for bank %s is not signed by its current key: %v", bankId, err)
+	}
+
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return err
+	}
+	bank.PublicKey = newPublicKey
+	bank.KeyVersion++
+	bank.KeyUpdatedAt = now.Format(time.RFC3339)
+	bank.UpdatedAt = bank.KeyUpdatedAt
+
+	return ctx.PutStateJSON(BankPrefix+bankId, bank)
+}
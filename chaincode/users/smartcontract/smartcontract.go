@@ -3,6 +3,7 @@ package smartcontract
 import (
 	"fmt"
 	"encoding/json"
+	"time"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
@@ -11,62 +12,130 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
+// NewSmartContract returns a SmartContract using the custom TransactionContext
+func NewSmartContract() *SmartContract {
+	contract := new(SmartContract)
+	contract.TransactionContextHandler = new(TransactionContext)
+	contract.BeforeTransaction = contract.validateParameters
+	return contract
+}
+
 // User Data struct
 type User struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	Email        string `json:"email"`
+	ID           string `json:"id" pattern:"^[0-9]+$" maxLength:"20"`
 	Transactions []Transaction `json:"transactions,omitempty" metadata:",optional"`
+	Transfers    []TransferEntry `json:"transfers,omitempty" metadata:",optional"`
+	Balances     map[string]string `json:"balances,omitempty" metadata:",optional"` // currency -> running balance of transfers
+	Archives     []string `json:"archives,omitempty" metadata:",optional"`     // IDs of the ArchiveSummary of archived transactions
+	ErasedAt     string `json:"erased_at,omitempty" metadata:",optional"`     // set on the tombstone left by EraseUserPersonalData
+	CreatedAt    string `json:"created_at,omitempty" metadata:",optional"`    // recorded at, from the transaction timestamp
+	UpdatedAt    string `json:"updated_at,omitempty" metadata:",optional"`
 }
 // Transaction Data struct
 type Transaction struct {
-	Hash         string `json:"hash"`
-	Amount       string `json:"amount"`
-	Currency     string `json:"currency"`
-	Date         string `json:"date"`
-	BankId       string `json:"bank_id"`
+	Hash         string `json:"hash" pattern:"^(0x)?[0-9a-fA-F]+$" maxLength:"66"`
+	Amount       string `json:"amount" pattern:"^[0-9]+(\\.[0-9]{1,8})?$" maxLength:"32"`
+	Currency     string `json:"currency" pattern:"^[A-Z]{3}$"`
+	Date         string `json:"date" format:"date"`                          // business date, as supplied by the client
+	BankId       string `json:"bank_id" pattern:"^[0-9]{8}$"`
+	Reference    string `json:"reference,omitempty" maxLength:"64" metadata:",optional"` // client reference, part of the hashed content
+	Signature    string `json:"signature,omitempty" metadata:",optional"`
+	TokenTransfer *TokenTransfer `json:"token_transfer,omitempty" metadata:",optional"`
+	Tags         []string `json:"tags,omitempty" metadata:",optional"`          // see TagTransaction
+	Category     string `json:"category,omitempty" metadata:",optional"`      // see CategorizeTransaction
+	StandingOrderID string `json:"standing_order_id,omitempty" metadata:",optional"` // set on transactions created by a StandingOrder
+	StandingOrderRun int `json:"standing_order_run,omitempty" metadata:",optional"` // run number of StandingOrderID, counted from 1
+	CreatedAt    string `json:"created_at,omitempty" metadata:",optional"`    // recorded at, from the transaction timestamp
+	UpdatedAt    string `json:"updated_at,omitempty" metadata:",optional"`
 }
 
 type TransactionHashMapUserId struct {
 	UserId           string `json:"user_id"`
+	ArchiveId        string `json:"archive_id,omitempty" metadata:",optional"` // set once the transaction is archived, see ArchiveTransactions
 }
 
 type Bank struct {
-	ID                 string `json:"id"`          // 統編
-	Name               string `json:"name"`
+	ID                 string `json:"id" pattern:"^[0-9]{8}$"`          // 統編
+	Name               string `json:"name" minLength:"1" maxLength:"64"`
 	TransactionCount   int    `json:"transaction_count"`
+	PublicKey          string `json:"public_key,omitempty" metadata:",optional"`     // PEM encoded ECDSA key signing the bank's transactions
+	KeyVersion         int    `json:"key_version,omitempty" metadata:",optional"`
+	KeyUpdatedAt       string `json:"key_updated_at,omitempty" metadata:",optional"`
+	CreatedAt          string `json:"created_at,omitempty" metadata:",optional"`
+	UpdatedAt          string `json:"updated_at,omitempty" metadata:",optional"`
+	MSPID              string `json:"msp_id,omitempty" metadata:",optional"`         // organization of the bank, see SetBankMSPID
 }
 
 const BankPrefix = "Bank_"    //前綴詞
 
-func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
+// AdminMSPID is the organization allowed to manage compliance settings
+const AdminMSPID = "Org1MSP"
+
+// requireAdmin rejects callers outside AdminMSPID
+func requireAdmin(ctx TransactionContextInterface) error {
+	mspID, err := ctx.GetCallerMSPID()
+	if err != nil {
+		return err
+	}
+	if mspID != AdminMSPID {
+		return fmt.Errorf("client from %s is not authorized to perform this operation", mspID)
+	}
+	return nil
+}
+
+// UserIDAttribute client certificate attribute, issued by the Fabric CA of a trusted MSP,
+// binding an identity to the user it acts for
+const UserIDAttribute = "user_id"
+
+// prefixRangeEnd returns the exclusive end key of a GetStateByRange over every key
+// starting with prefix
+func prefixRangeEnd(prefix string) string {
+	return prefix[:len(prefix)-1] + string(prefix[len(prefix)-1]+1)
+}
+
+// recordedAt returns the transaction timestamp formatted for the CreatedAt and UpdatedAt
+// fields. Unlike a client supplied date it is the same on every endorsing peer
+func recordedAt(ctx TransactionContextInterface) (string, error) {
+	now, err := ctx.GetTxTime()
+	if err != nil {
+		return "", err
+	}
+	return now.Format(time.RFC3339), nil
+}
+
+func (s *SmartContract) InitLedger(ctx TransactionContextInterface) error {
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
 	var cathayBank Bank = Bank{
 		ID: "04231910",
 		Name: "國泰世華商業銀行",
 		TransactionCount: 0,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 	var fubonBank Bank = Bank{
 			ID: "03750168",
 			Name: "台北富邦商業銀行",
 			TransactionCount: 0,
+			CreatedAt: now,
+			UpdatedAt: now,
 	}
 
-	cathayBankJson, err := json.Marshal(cathayBank)
+	err = ctx.PutStateJSON(BankPrefix+cathayBank.ID, cathayBank)
 	if err != nil {
 		return err
 	}
-	fubonBankJson, err := json.Marshal(fubonBank)
+	err = ctx.PutStateJSON(BankPrefix+fubonBank.ID, fubonBank)
 	if err != nil {
 		return err
 	}
-	
-	ctx.GetStub().PutState(BankPrefix+cathayBank.ID, cathayBankJson)
-	ctx.GetStub().PutState(BankPrefix+fubonBank.ID, fubonBankJson)
 
 	return nil
 }
 
-func (s *SmartContract) UserExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+func (s *SmartContract) UserExists(ctx TransactionContextInterface, id string) (bool, error) {
 	assetJSON, err := ctx.GetStub().GetState(id)
 	if err != nil {
 		return false, fmt.Errorf("failed to read from world state: %v", err)
@@ -74,7 +143,21 @@ func (s *SmartContract) UserExists(ctx contractapi.TransactionContextInterface,
 	return assetJSON != nil, nil
 }
 
-func (s *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface, id string, name string, email string) error {
+// CreateUser registers a new user. Its PersonalData, name and email included, must be
+// supplied in the transient map under PersonalDataTransientKey so that it stays off the
+// public ledger. A client request ID may be supplied under IdempotencyTransientKey to make
+// retries safe and an AES key under EncryptionKeyTransientKey to store the name and email
+// encrypted
+func (s *SmartContract) CreateUser(ctx TransactionContextInterface, id string) error {
+	request := []string{id}
+	idempotencyKey, replay, err := idempotentReplay(ctx, request)
+	if err != nil {
+		return err
+	}
+	if replay != nil {
+		return nil
+	}
+
 	exists, err := s.UserExists(ctx, id)
 	if err != nil {
 		return err
@@ -82,66 +165,161 @@ func (s *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface,
 	if exists {
 		return fmt.Errorf("the user %s already exists", id)
 	}
+	personalData, err := transientPersonalData(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
 
 	user := User{
-		ID:    id,
-		Name:  name,
-		Email: email,
+		ID:        id,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
-	userJson, err := json.Marshal(user)
+	err = s.storeUserData(ctx, &user, personalData, "")
 	if err != nil {
 		return err
 	}
-	
-	return ctx.GetStub().PutState(id, userJson)
+
+	err = ctx.PutStateJSON(id, user)
+	if err != nil {
+		return err
+	}
+
+	return recordIdempotentResult(ctx, idempotencyKey, request, nil)
 }
 
-func (s *SmartContract) GetUser(ctx contractapi.TransactionContextInterface, id string) (*User, error) {
-	userJson, err := ctx.GetStub().GetState(id)
+// storeUserData screens user by personalData, then stores its PersonalData, its
+// ScreeningData and its name index entry, replacing oldIndexKey
+func (s *SmartContract) storeUserData(ctx TransactionContextInterface, user *User, personalData *PersonalData, oldIndexKey string) error {
+	data := newScreeningData(user.ID, personalData)
+	err := s.screenUser(ctx, user, data, user.ID+"_"+ctx.GetStub().GetTxID(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
+		return err
 	}
-	if userJson == nil {
-		return nil, fmt.Errorf("the user %s does not exist", id)
+	err = storeScreeningData(ctx, user.ID, data)
+	if err != nil {
+		return err
+	}
+	err = storePersonalData(ctx, user.ID, personalData)
+	if err != nil {
+		return err
 	}
+	return updateNameIndex(ctx, oldIndexKey, data.IndexKey)
+}
 
+// GetUser returns the public record of user id, see GetUserPersonalData for its name and
+// email. Banks need the user's consent, see GrantConsent
+func (s *SmartContract) GetUser(ctx TransactionContextInterface, id string) (*User, error) {
+	err := s.requireConsent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.readUser(ctx, id)
+}
+
+// readUser returns the user id without checking the caller's consent
+func (s *SmartContract) readUser(ctx TransactionContextInterface, id string) (*User, error) {
 	var user User
-	err = json.Unmarshal(userJson, &user)
+	exists, err := ctx.GetStateJSON(id, &user)
 	if err != nil {
 		return nil, err
 	}
+	if !exists {
+		return nil, fmt.Errorf("the user %s does not exist", id)
+	}
 
 	return &user, nil
 }
 
-func (s *SmartContract) UpdateUser(ctx contractapi.TransactionContextInterface, id string, name string, email string) error {
-	user, err := s.GetUser(ctx, id)
+// UpdateUser replaces the PersonalData of a user, supplied in the transient map as in
+// CreateUser and encrypted when a key is supplied
+func (s *SmartContract) UpdateUser(ctx TransactionContextInterface, id string) error {
+	user, err := s.readUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkNotErased(user)
+	if err != nil {
+		return err
+	}
+	personalData, err := transientPersonalData(ctx)
 	if err != nil {
 		return err
 	}
-	user.Email = email
-	user.Name = name
-	userJson, err := json.Marshal(user)
+	oldData, err := lookupScreeningData(ctx, id)
+	if err != nil {
+		return err
+	}
+	oldIndexKey := ""
+	if oldData != nil {
+		oldIndexKey = oldData.IndexKey
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	user.UpdatedAt = now
+	err = s.storeUserData(ctx, user, personalData, oldIndexKey)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, userJson)
+	return ctx.PutStateJSON(id, user)
 }
 
-func (s *SmartContract) DeleteUser(ctx contractapi.TransactionContextInterface, id string) error {
-	exists, err := s.UserExists(ctx, id)
+// DeleteUser removes a user with the index entries and hash mappings of its transactions,
+// its personal data, consents, Merkle tree and token account link, and cancels its
+// standing orders
+func (s *SmartContract) DeleteUser(ctx TransactionContextInterface, id string) error {
+	user, err := s.readUser(ctx, id)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("the user %s does not exist", id)
+	for _, transaction := range user.Transactions {
+		err = unindexTransaction(ctx, id, transaction)
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().DelState(transaction.Hash)
+		if err != nil {
+			return err
+		}
+	}
+	err = removeNameIndex(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, collection := range []string{PersonalDataCollection, LookupCollection} {
+		_, err = removePrivateData(ctx, collection, id)
+		if err != nil {
+			return err
+		}
+	}
+	err = deleteUserConsents(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = s.deleteMerkleTree(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = cancelUserStandingOrders(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = unlinkTokenAccount(ctx, id)
+	if err != nil {
+		return err
 	}
 
 	return ctx.GetStub().DelState(id)
 }
 
-func (s *SmartContract) GetAllUsers(ctx contractapi.TransactionContextInterface) ([]*User, error) {
+func (s *SmartContract) GetAllUsers(ctx TransactionContextInterface) ([]*User, error) {
 	resultsIterator, err := ctx.GetStub().GetStateByRange("0", "99999")
 	if err != nil {
 		return nil, err
@@ -160,16 +338,53 @@ func (s *SmartContract) GetAllUsers(ctx contractapi.TransactionContextInterface)
 		if err != nil {
 			return nil, err
 		}
+		allowed, err := s.hasConsent(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
 		users = append(users, &user)
 	}
 
 	return users, nil
 }
 
-func (s *SmartContract) CreateTransaction(ctx contractapi.TransactionContextInterface, userId string, hash string, amount string, currency string, date string, bankId string) (bool, error) {
-	user, err := s.GetUser(ctx, userId)
+// CreateTransaction records a transaction for a user. reference is the client's own
+// reference, which may be empty, telling apart identical payments of the same day. hash
+// is recorded as lower case hex without 0x prefix. signature is the base64 ECDSA
+// signature of the bank bankId over CanonicalTransaction. A client request ID may be
+// supplied in the transient map under IdempotencyTransientKey to make retries safe.
+// Returns the recorded hash
+func (s *SmartContract) CreateTransaction(ctx TransactionContextInterface, userId string, hash string, amount string, currency string, date string, bankId string, reference string, signature string) (string, error) {
+	// a retry is signed again, the signature is no part of the request
+	request := []string{userId, normalizeHash(hash), amount, currency, date, bankId, reference}
+	idempotencyKey, replay, err := idempotentReplay(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	if replay != nil {
+		var recordedHash string
+		err = json.Unmarshal(replay.Result, &recordedHash)
+		return recordedHash, err
+	}
+
+	user, err := s.readUser(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	err = checkNotErased(user)
+	if err != nil {
+		return "", err
+	}
+	bank, err := s.GetBankByID(ctx, bankId)
 	if err != nil {
-		return false, err
+		return "", err
+	}
+	err = requireBankMSP(ctx, bank)
+	if err != nil {
+		return "", err
 	}
 
 	var transaction Transaction = Transaction{
@@ -177,79 +392,149 @@ func (s *SmartContract) CreateTransaction(ctx contractapi.TransactionContextInte
 		Amount:    amount,
 		Currency:  currency,
 		Date:      date,
+		BankId:    bankId,
+		Reference: reference,
+		Signature: signature,
+	}
+	err = checkTransactionHash(user.ID, transaction)
+	if err != nil {
+		return "", err
+	}
+	transaction.Hash = normalizeHash(hash)
+	err = checkBankSignature(bank, user.ID, transaction, signature)
+	if err != nil {
+		return "", err
+	}
+	alerts, err := s.recordTransaction(ctx, user, bank, transaction, true)
+	if err != nil {
+		return "", err
+	}
+	err = emitAMLAlerts(ctx, alerts)
+	if err != nil {
+		return "", err
 	}
-	user.Transactions = append(user.Transactions, transaction)
 
-	userJson, err := json.Marshal(user)
+	err = recordIdempotentResult(ctx, idempotencyKey, request, transaction.Hash)
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
-	ctx.GetStub().PutState(userId, userJson)
+	return transaction.Hash, nil
+}
 
-	var transactionHashMapUserId TransactionHashMapUserId = TransactionHashMapUserId{
-		UserId:      user.ID,
+// recordTransaction screens a verified transaction and records it for user at bank,
+// maintaining the hash map, the indexes, the AML totals and the bank count. settle runs
+// the optional token settlement requested in the transient map. A transaction already
+// recorded or of a user matching a blocking watchlist entry is rejected before any write.
+// It returns the AML alerts raised, for the caller to emit, see emitAMLAlerts
+func (s *SmartContract) recordTransaction(ctx TransactionContextInterface, user *User, bank *Bank, transaction Transaction, settle bool) ([]*Alert, error) {
+	recorded, err := ctx.GetStub().GetState(transaction.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
 	}
+	if recorded != nil {
+		return nil, fmt.Errorf("the transaction %s already exists", transaction.Hash)
+	}
+	err = s.screenUser(ctx, user, nil, transaction.Hash, &transaction)
+	if err != nil {
+		return nil, err
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
 
-	transactionHashMapUserIdJson, err := json.Marshal(transactionHashMapUserId)
+	// optional settlement through the token chaincode, see SettlementTransientKey
+	if settle {
+		transaction.TokenTransfer, err = s.settleTransaction(ctx, transaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.appendTransactionLeaf(ctx, user, transaction.Hash)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
+	user.Transactions = append(user.Transactions, transaction)
+	user.UpdatedAt = now
 
-	ctx.GetStub().PutState(hash, transactionHashMapUserIdJson)
+	alerts, err := s.screenTransaction(ctx, user.ID, transaction)
+	if err != nil {
+		return nil, err
+	}
 
-	// add bank count
-	bank, err := s.GetBankByID(ctx, bankId)
+	err = ctx.PutStateJSON(user.ID, user)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	bank.TransactionCount++
 
-	bankJson, err := json.Marshal(bank)
+	var transactionHashMapUserId TransactionHashMapUserId = TransactionHashMapUserId{
+		UserId:      user.ID,
+	}
+
+	err = ctx.PutStateJSON(transaction.Hash, transactionHashMapUserId)
+	if err != nil {
+		return nil, err
+	}
+	err = s.indexTransaction(ctx, transaction)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	ctx.GetStub().PutState(BankPrefix+bankId, bankJson)
+	// add bank count
+	bank.TransactionCount++
+	bank.UpdatedAt = now
 
-	return true, nil
+	err = ctx.PutStateJSON(BankPrefix+bank.ID, bank)
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
 }
 
-func (s *SmartContract) GetUserByTransactionHash(ctx contractapi.TransactionContextInterface, hash string) (*User, error) {
-	transactionHashMapUserIdJson, err := ctx.GetStub().GetState(hash)
+// GetUserByTransactionHash returns the user the transaction hash is recorded for. Banks need
+// the user's consent, see GrantConsent
+func (s *SmartContract) GetUserByTransactionHash(ctx TransactionContextInterface, hash string) (*User, error) {
+	user, err := s.readUserByTransactionHash(ctx, hash)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
+		return nil, err
 	}
-	if transactionHashMapUserIdJson == nil {
-		return nil, fmt.Errorf("the transaction %s does not exist", hash)
+	err = s.requireConsent(ctx, user.ID)
+	if err != nil {
+		return nil, err
 	}
+	return user, nil
+}
+
+// readUserByTransactionHash is GetUserByTransactionHash without the consent check
+func (s *SmartContract) readUserByTransactionHash(ctx TransactionContextInterface, hash string) (*User, error) {
 	var transactionHashMapUserId TransactionHashMapUserId
-	err = json.Unmarshal(transactionHashMapUserIdJson, &transactionHashMapUserId)
+	exists, err := ctx.GetStateJSON(normalizeHash(hash), &transactionHashMapUserId)
 	if err != nil {
 		return nil, err
 	}
+	if !exists {
+		return nil, fmt.Errorf("the transaction %s does not exist", hash)
+	}
 
-	user, err := s.GetUser(ctx, transactionHashMapUserId.UserId)
+	user, err := s.readUser(ctx, transactionHashMapUserId.UserId)
 	if err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
-func (s *SmartContract) GetBankByID(ctx contractapi.TransactionContextInterface, bankId string) (*Bank, error) {
-	bankJson, err := ctx.GetStub().GetState(BankPrefix+bankId)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
-	}
-	if bankJson == nil {
-		return nil, fmt.Errorf("the bankJson %s does not exist", BankPrefix+bankId)
-	}
+func (s *SmartContract) GetBankByID(ctx TransactionContextInterface, bankId string) (*Bank, error) {
 	var bank Bank
-	err = json.Unmarshal(bankJson, &bank)
-	fmt.Println(string(bankJson))
+	exists, err := ctx.GetStateJSON(BankPrefix+bankId, &bank)
 	if err != nil {
 		return nil, err
 	}
+	if !exists {
+		return nil, fmt.Errorf("the bankJson %s does not exist", BankPrefix+bankId)
+	}
 
 	return &bank, nil
 }
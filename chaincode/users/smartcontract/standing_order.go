@@ -0,0 +1,354 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const StandingOrderPrefix = "StandingOrder_"
+
+const (
+	ScheduleDaily   = "daily"
+	ScheduleWeekly  = "weekly"
+	ScheduleMonthly = "monthly"
+
+	StandingOrderActive    = "active"
+	StandingOrderCancelled = "cancelled"
+)
+
+// StandingOrder recurring transaction a bank records for a user on schedule, starting on
+// StartDate. A monthly order keeps the day of month of StartDate, falling back to the last
+// day of shorter months
+type StandingOrder struct {
+	ID          string `json:"id"`
+	UserId      string `json:"user_id"`
+	Amount      string `json:"amount"`
+	Currency    string `json:"currency"`
+	BankId      string `json:"bank_id"`
+	Schedule    string `json:"schedule" pattern:"^(daily|weekly|monthly)$"`
+	StartDate   string `json:"start_date"`
+	NextRunDate string `json:"next_run_date"`
+	RunCount    int    `json:"run_count"`
+	Status      string `json:"status"`
+	CreatedBy   string `json:"created_by"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// StandingOrderExecution result of ExecuteDueStandingOrders. Pending counts the orders
+// still due as of AsOf, see ExecuteDueStandingOrders
+type StandingOrderExecution struct {
+	AsOf         string                  `json:"as_of"`
+	Transactions []*TransactionRecord    `json:"transactions"`
+	Failed       []*StandingOrderFailure `json:"failed"`
+	Pending      int                     `json:"pending"`
+}
+
+// StandingOrderFailure standing order whose run could not be recorded. The order is left
+// due and retried by the next ExecuteDueStandingOrders
+type StandingOrderFailure struct {
+	OrderID string `json:"order_id"`
+	UserId  string `json:"user_id"`
+	Error   string `json:"error"`
+}
+
+// runDate returns the date of run n, counted from 0, of a schedule starting on start
+func runDate(start time.Time, schedule string, n int) time.Time {
+	switch schedule {
+	case ScheduleDaily:
+		return start.AddDate(0, 0, n)
+	case ScheduleWeekly:
+		return start.AddDate(0, 0, 7*n)
+	}
+	firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := start.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// CreateStandingOrder records a recurring transaction of amount for userId at bankId on a
+// ScheduleDaily, ScheduleWeekly or ScheduleMonthly schedule, first run on the day of the
+// Fabric transaction. The order stands in for the bank signature of the transactions it
+// creates, so only the bank itself may create it. Returns the order ID
+func (s *SmartContract) CreateStandingOrder(ctx TransactionContextInterface, userId string, amount string, currency string, bankId string, schedule string) (string, error) {
+	if schedule != ScheduleDaily && schedule != ScheduleWeekly && schedule != ScheduleMonthly {
+		return "", fmt.Errorf("unknown schedule %q, expected %s, %s or %s", schedule, ScheduleDaily, ScheduleWeekly, ScheduleMonthly)
+	}
+	value, err := parseAmount(amount)
+	if err != nil {
+		return "", err
+	}
+	if value.Sign() <= 0 {
+		return "", fmt.Errorf("amount %s must be positive", amount)
+	}
+	user, err := s.readUser(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	err = checkNotErased(user)
+	if err != nil {
+		return "", err
+	}
+	bank, err := s.GetBankByID(ctx, bankId)
+	if err != nil {
+		return "", err
+	}
+	err = requireBankMSP(ctx, bank)
+	if err != nil {
+		return "", err
+	}
+	createdBy, err := ctx.GetCallerID()
+	if err != nil {
+		return "", err
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	order := StandingOrder{
+		ID:          ctx.GetStub().GetTxID(),
+		UserId:      userId,
+		Amount:      amount,
+		Currency:    currency,
+		BankId:      bankId,
+		Schedule:    schedule,
+		StartDate:   now[:len("2006-01-02")],
+		NextRunDate: now[:len("2006-01-02")],
+		Status:      StandingOrderActive,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = ctx.PutStateJSON(StandingOrderPrefix+order.ID, order)
+	if err != nil {
+		return "", err
+	}
+	return order.ID, nil
+}
+
+// GetStandingOrder returns the standing order id. Subject to the same consent as the data
+// of the user it pays for
+func (s *SmartContract) GetStandingOrder(ctx TransactionContextInterface, id string) (*StandingOrder, error) {
+	order, err := s.readStandingOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.requireConsent(ctx, order.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// readStandingOrder is GetStandingOrder without the consent check
+func (s *SmartContract) readStandingOrder(ctx TransactionContextInterface, id string) (*StandingOrder, error) {
+	var order StandingOrder
+	exists, err := ctx.GetStateJSON(StandingOrderPrefix+id, &order)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the standing order %s does not exist", id)
+	}
+	return &order, nil
+}
+
+// CancelStandingOrder stops a standing order. Owner or bank of the order only
+func (s *SmartContract) CancelStandingOrder(ctx TransactionContextInterface, id string) error {
+	order, err := s.readStandingOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+	if requireUser(ctx, order.UserId) != nil {
+		bank, err := s.GetBankByID(ctx, order.BankId)
+		if err != nil {
+			return err
+		}
+		err = requireBankMSP(ctx, bank)
+		if err != nil {
+			return err
+		}
+	}
+	if order.Status != StandingOrderActive {
+		return fmt.Errorf("the standing order %s is already %s", id, order.Status)
+	}
+	order.Status = StandingOrderCancelled
+	order.UpdatedAt, err = recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.PutStateJSON(StandingOrderPrefix+id, order)
+}
+
+// cancelUserStandingOrders cancels the active standing orders of userId
+func cancelUserStandingOrders(ctx TransactionContextInterface, userId string) error {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(StandingOrderPrefix, prefixRangeEnd(StandingOrderPrefix))
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	var orders []*StandingOrder
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+		var order StandingOrder
+		err = json.Unmarshal(queryResponse.Value, &order)
+		if err != nil {
+			return err
+		}
+		if order.UserId == userId && order.Status == StandingOrderActive {
+			orders = append(orders, &order)
+		}
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		order.Status = StandingOrderCancelled
+		order.UpdatedAt = now
+		err = ctx.PutStateJSON(StandingOrderPrefix+order.ID, order)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExecuteDueStandingOrders records a Transaction, dated on its run date, for every active
+// standing order whose next run is on or before asOf, which must not be after the date of
+// the Fabric transaction, and advances the order to its next run. Orders are taken in ID
+// order. Fabric transactions do not read their own writes, so the daily AML totals of a
+// user would be lost if the user had several runs in one call: at most one run per user
+// is executed and orders still due are counted as pending, to be executed by calling
+// again. Orders of erased users are cancelled. An order whose run is rejected, its user
+// deleted or matching a blocking watchlist entry, is skipped and reported under Failed
+// without stopping the others. Admin only
+func (s *SmartContract) ExecuteDueStandingOrders(ctx TransactionContextInterface, asOf string) (*StandingOrderExecution, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	asOfDate, err := time.Parse("2006-01-02", asOf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", asOf)
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if asOf > now[:len("2006-01-02")] {
+		return nil, fmt.Errorf("standing orders cannot be executed as of %s, after the transaction date %s", asOf, now[:len("2006-01-02")])
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByRange(StandingOrderPrefix, prefixRangeEnd(StandingOrderPrefix))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+	var orders []*StandingOrder
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var order StandingOrder
+		err = json.Unmarshal(queryResponse.Value, &order)
+		if err != nil {
+			return nil, err
+		}
+		if order.Status == StandingOrderActive && order.NextRunDate <= asOf {
+			orders = append(orders, &order)
+		}
+	}
+
+	execution := StandingOrderExecution{AsOf: asOf, Transactions: []*TransactionRecord{}, Failed: []*StandingOrderFailure{}}
+	fail := func(order *StandingOrder, err error) {
+		execution.Failed = append(execution.Failed, &StandingOrderFailure{OrderID: order.ID, UserId: order.UserId, Error: err.Error()})
+	}
+	executed := map[string]bool{}
+	// Fabric keeps only the last event of a transaction, the alerts of every run are
+	// emitted together
+	var alerts []*Alert
+	// banks are shared between users, the same record is updated for every run
+	banks := map[string]*Bank{}
+	for _, order := range orders {
+		if executed[order.UserId] {
+			execution.Pending++
+			continue
+		}
+		user, err := s.readUser(ctx, order.UserId)
+		if err != nil {
+			fail(order, err)
+			continue
+		}
+		order.UpdatedAt = now
+		if checkNotErased(user) != nil {
+			order.Status = StandingOrderCancelled
+			err = ctx.PutStateJSON(StandingOrderPrefix+order.ID, order)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		bank, ok := banks[order.BankId]
+		if !ok {
+			bank, err = s.GetBankByID(ctx, order.BankId)
+			if err != nil {
+				fail(order, err)
+				continue
+			}
+			banks[order.BankId] = bank
+		}
+
+		transaction := Transaction{
+			Amount:           order.Amount,
+			Currency:         order.Currency,
+			Date:             order.NextRunDate,
+			BankId:           order.BankId,
+			StandingOrderID:  order.ID,
+			StandingOrderRun: order.RunCount + 1,
+		}
+		transaction.Hash, err = TransactionHash(user.ID, transaction)
+		if err != nil {
+			fail(order, err)
+			continue
+		}
+		orderAlerts, err := s.recordTransaction(ctx, user, bank, transaction, false)
+		if err != nil {
+			fail(order, err)
+			continue
+		}
+		alerts = append(alerts, orderAlerts...)
+		executed[order.UserId] = true
+		execution.Transactions = append(execution.Transactions, &TransactionRecord{UserId: user.ID, Transaction: user.Transactions[len(user.Transactions)-1]})
+
+		start, err := time.Parse("2006-01-02", order.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		order.RunCount++
+		next := runDate(start, order.Schedule, order.RunCount)
+		order.NextRunDate = next.Format("2006-01-02")
+		if !next.After(asOfDate) {
+			execution.Pending++
+		}
+		err = ctx.PutStateJSON(StandingOrderPrefix+order.ID, order)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = emitAMLAlerts(ctx, alerts)
+	if err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
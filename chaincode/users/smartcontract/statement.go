@@ -0,0 +1,132 @@
+package smartcontract
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Statement summary of a user's transactions for one calendar month
+type Statement struct {
+	UserId       string               `json:"user_id"`
+	Month        string               `json:"month"`
+	OpeningCount int                  `json:"opening_count"` // transactions dated before the month
+	ClosingCount int                  `json:"closing_count"` // transactions dated up to the end of the month
+	Totals       map[string]string    `json:"totals"`        // currency -> sum of the month
+	Banks        []*BankStatement     `json:"banks"`
+	Categories   []*CategoryStatement `json:"categories"`
+	Transactions []Transaction        `json:"transactions"`
+}
+
+// BankStatement part of a Statement for transactions at one bank
+type BankStatement struct {
+	BankId string            `json:"bank_id"`
+	Count  int               `json:"count"`
+	Totals map[string]string `json:"totals"`
+}
+
+// addToTotals adds amount to totals[currency]
+func addToTotals(totals map[string]string, currency string, amount string) error {
+	total, ok := totals[currency]
+	if !ok {
+		total = "0"
+	}
+	total, err := addAmounts(total, amount)
+	if err != nil {
+		return err
+	}
+	totals[currency] = total
+	return nil
+}
+
+// GetUserStatement returns the statement of userId for month, formatted yyyy-mm, computed
+// from the transactions stored on the user. Archived transactions count through their
+// ArchiveSummary, a month with archived transactions has no statement
+func (s *SmartContract) GetUserStatement(ctx TransactionContextInterface, userId string, month string) (*Statement, error) {
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return nil, fmt.Errorf("month %q is not formatted yyyy-mm", month)
+	}
+	user, err := s.GetUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := Statement{
+		UserId:       user.ID,
+		Month:        month,
+		Totals:       map[string]string{},
+		Banks:        []*BankStatement{},
+		Categories:   []*CategoryStatement{},
+		Transactions: []Transaction{},
+	}
+	for _, id := range user.Archives {
+		var summary ArchiveSummary
+		exists, err := ctx.GetStateJSON(ArchivePrefix+id, &summary)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("the archive %s does not exist", id)
+		}
+		if summary.BeforeDate > month+"-01" {
+			return nil, fmt.Errorf("the transactions of user %s dated before %s are archived, no statement can be produced for %s", user.ID, summary.BeforeDate, month)
+		}
+		statement.OpeningCount += summary.Count
+		statement.ClosingCount += summary.Count
+	}
+	banks := map[string]*BankStatement{}
+	categories := map[string]*CategoryStatement{}
+	for _, transaction := range user.Transactions {
+		if transaction.Date < month {
+			statement.OpeningCount++
+			statement.ClosingCount++
+			continue
+		}
+		if !strings.HasPrefix(transaction.Date, month) {
+			continue
+		}
+		statement.ClosingCount++
+		statement.Transactions = append(statement.Transactions, transaction)
+
+		err = addToTotals(statement.Totals, transaction.Currency, transaction.Amount)
+		if err != nil {
+			return nil, err
+		}
+		bank, ok := banks[transaction.BankId]
+		if !ok {
+			bank = &BankStatement{BankId: transaction.BankId, Totals: map[string]string{}}
+			banks[transaction.BankId] = bank
+			statement.Banks = append(statement.Banks, bank)
+		}
+		bank.Count++
+		err = addToTotals(bank.Totals, transaction.Currency, transaction.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		name := transaction.Category
+		if name == "" {
+			name = CategoryUncategorized
+		}
+		category, ok := categories[name]
+		if !ok {
+			category = &CategoryStatement{Category: name, Totals: map[string]string{}}
+			categories[name] = category
+			statement.Categories = append(statement.Categories, category)
+		}
+		category.Count++
+		err = addToTotals(category.Totals, transaction.Currency, transaction.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(statement.Banks, func(i, j int) bool {
+		return statement.Banks[i].BankId < statement.Banks[j].BankId
+	})
+	sort.Slice(statement.Categories, func(i, j int) bool {
+		return statement.Categories[i].Category < statement.Categories[j].Category
+	})
+
+	return &statement, nil
+}
@@ -0,0 +1,171 @@
+package smartcontract
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// txByTagIndex composite key txByTag~userId~tag~hash listing the transactions of a user
+// carrying a tag. Values are a single 0x00 byte
+const txByTagIndex = "txByTag"
+
+// CategoryUncategorized groups the transactions without a category in statements
+const CategoryUncategorized = "uncategorized"
+
+var labelPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// CategoryStatement part of a Statement for transactions of one category
+type CategoryStatement struct {
+	Category string            `json:"category"`
+	Count    int               `json:"count"`
+	Totals   map[string]string `json:"totals"`
+}
+
+// parseTags splits a comma separated list of tags, lower cased, sorted and without
+// duplicates
+func parseTags(tags string) ([]string, error) {
+	seen := map[string]bool{}
+	parsed := []string{}
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if !labelPattern.MatchString(tag) {
+			return nil, fmt.Errorf("tag %q must be 1 to 32 lower case letters, digits, _ or -", tag)
+		}
+		seen[tag] = true
+		parsed = append(parsed, tag)
+	}
+	sort.Strings(parsed)
+	return parsed, nil
+}
+
+// tagIndexKeys returns the tag index keys of transaction recorded for userId
+func tagIndexKeys(ctx TransactionContextInterface, userId string, transaction Transaction) ([]string, error) {
+	var keys []string
+	for _, tag := range transaction.Tags {
+		key, err := ctx.GetStub().CreateCompositeKey(txByTagIndex, []string{userId, tag, transaction.Hash})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", txByTagIndex, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// findLabelledTransaction returns the owner and position of the transaction with hash
+// when the caller is its owner or its bank
+func (s *SmartContract) findLabelledTransaction(ctx TransactionContextInterface, hash string) (*User, int, error) {
+	user, i, err := s.findTransaction(ctx, hash)
+	if err != nil {
+		return nil, -1, err
+	}
+	if requireUser(ctx, user.ID) != nil {
+		bank, err := s.GetBankByID(ctx, user.Transactions[i].BankId)
+		if err != nil {
+			return nil, -1, err
+		}
+		err = requireBankMSP(ctx, bank)
+		if err != nil {
+			return nil, -1, err
+		}
+	}
+	return user, i, nil
+}
+
+// TagTransaction replaces the tags of a transaction by tags, a comma separated list, an
+// empty list removing them. Owner or bank of the transaction only
+func (s *SmartContract) TagTransaction(ctx TransactionContextInterface, hash string, tags string) error {
+	parsed, err := parseTags(tags)
+	if err != nil {
+		return err
+	}
+	user, i, err := s.findLabelledTransaction(ctx, hash)
+	if err != nil {
+		return err
+	}
+	transaction := &user.Transactions[i]
+
+	oldKeys, err := tagIndexKeys(ctx, user.ID, *transaction)
+	if err != nil {
+		return err
+	}
+	for _, key := range oldKeys {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return err
+		}
+	}
+	transaction.Tags = parsed
+	keys, err := tagIndexKeys(ctx, user.ID, *transaction)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		err = ctx.GetStub().PutState(key, []byte{0x00})
+		if err != nil {
+			return err
+		}
+	}
+
+	transaction.UpdatedAt, err = recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.PutStateJSON(user.ID, user)
+}
+
+// CategorizeTransaction sets the category of a transaction, such as salary, rent or
+// transfer, an empty category removing it. Owner or bank of the transaction only
+func (s *SmartContract) CategorizeTransaction(ctx TransactionContextInterface, hash string, category string) error {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category != "" && !labelPattern.MatchString(category) {
+		return fmt.Errorf("category %q must be 1 to 32 lower case letters, digits, _ or -", category)
+	}
+	user, i, err := s.findLabelledTransaction(ctx, hash)
+	if err != nil {
+		return err
+	}
+	transaction := &user.Transactions[i]
+	transaction.Category = category
+	transaction.UpdatedAt, err = recordedAt(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.PutStateJSON(user.ID, user)
+}
+
+// ListTransactionsByTag returns the transactions of userId carrying tag, ordered by hash.
+// Subject to the same consent as the user's data
+func (s *SmartContract) ListTransactionsByTag(ctx TransactionContextInterface, userId string, tag string) ([]Transaction, error) {
+	user, err := s.GetUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.indexKeys(ctx, txByTagIndex, []string{userId, strings.ToLower(strings.TrimSpace(tag))})
+	if err != nil {
+		return nil, err
+	}
+	tagged := map[string]bool{}
+	for _, key := range keys {
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		tagged[attributes[len(attributes)-1]] = true
+	}
+
+	transactions := []Transaction{}
+	for _, transaction := range user.Transactions {
+		if tagged[transaction.Hash] {
+			transactions = append(transactions, transaction)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].Hash < transactions[j].Hash
+	})
+	return transactions, nil
+}
@@ -0,0 +1,140 @@
+package smartcontract
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const TokenAccountPrefix = "TokenAccount_"
+
+// tokenAccountUserPrefix starts the reverse lookup keys from a token account to the user
+// it is linked to
+const tokenAccountUserPrefix = "TokenAccountUser_"
+
+// TokenAccount link between a user and a token-erc-20 account. AccountID is the client ID
+// the token chaincode returns from ClientAccountID, which is the ID of the identity that
+// called LinkTokenAccount
+type TokenAccount struct {
+	UserId    string `json:"user_id"`
+	AccountID string `json:"account_id"`
+	LinkedAt  string `json:"linked_at"`
+}
+
+// LinkTokenAccount links userId to the token account of the calling identity, replacing
+// any previous link. The identity must carry the user_id attribute of userId, which
+// proves the account and the user belong together
+func (s *SmartContract) LinkTokenAccount(ctx TransactionContextInterface, userId string) (*TokenAccount, error) {
+	err := requireUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.readUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	err = checkNotErased(user)
+	if err != nil {
+		return nil, err
+	}
+	accountId, err := ctx.GetCallerID()
+	if err != nil {
+		return nil, err
+	}
+	linkedUser, err := ctx.GetStub().GetState(tokenAccountUserPrefix + accountId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if linkedUser != nil && string(linkedUser) != userId {
+		return nil, fmt.Errorf("the token account is already linked to user %s", linkedUser)
+	}
+	err = unlinkTokenAccount(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := TokenAccount{
+		UserId:    userId,
+		AccountID: accountId,
+		LinkedAt:  now,
+	}
+	err = ctx.PutStateJSON(TokenAccountPrefix+userId, account)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(tokenAccountUserPrefix+accountId, []byte(userId))
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// unlinkTokenAccount removes the token account link of userId, if any
+func unlinkTokenAccount(ctx TransactionContextInterface, userId string) error {
+	var account TokenAccount
+	exists, err := ctx.GetStateJSON(TokenAccountPrefix+userId, &account)
+	if err != nil || !exists {
+		return err
+	}
+	err = ctx.GetStub().DelState(tokenAccountUserPrefix + account.AccountID)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().DelState(TokenAccountPrefix + userId)
+}
+
+// GetTokenAccount returns the token account linked to userId. Subject to the same consent
+// as the user's data
+func (s *SmartContract) GetTokenAccount(ctx TransactionContextInterface, userId string) (*TokenAccount, error) {
+	err := s.requireConsent(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	var account TokenAccount
+	exists, err := ctx.GetStateJSON(TokenAccountPrefix+userId, &account)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the user %s has no linked token account", userId)
+	}
+	return &account, nil
+}
+
+// GetUserByTokenAccount returns the user linked to the token account accountId. Subject
+// to the same consent as the user's data
+func (s *SmartContract) GetUserByTokenAccount(ctx TransactionContextInterface, accountId string) (*User, error) {
+	userId, err := ctx.GetStub().GetState(tokenAccountUserPrefix + accountId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if userId == nil {
+		return nil, fmt.Errorf("the token account %s is not linked to a user", accountId)
+	}
+	return s.GetUser(ctx, string(userId))
+}
+
+// GetUserTokenBalance returns the balance of the token account linked to userId, queried
+// from BalanceOf of the token chaincode of the same channel
+func (s *SmartContract) GetUserTokenBalance(ctx TransactionContextInterface, userId string) (int, error) {
+	account, err := s.GetTokenAccount(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+	args := [][]byte{
+		[]byte("BalanceOf"),
+		[]byte(account.AccountID),
+	}
+	response := ctx.GetStub().InvokeChaincode(TokenChaincodeName, args, "")
+	if response.Status >= 400 {
+		return 0, fmt.Errorf("balance query for token account of user %s was rejected: %s", userId, response.Message)
+	}
+	balance, err := strconv.Atoi(string(response.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("token chaincode returned an invalid balance %q", response.Payload)
+	}
+	return balance, nil
+}
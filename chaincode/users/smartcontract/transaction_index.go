@@ -0,0 +1,236 @@
+package smartcontract
+
+import (
+	"fmt"
+	"time"
+)
+
+// Composite key indexes maintained by CreateTransaction. Their values are a single 0x00
+// byte, the transaction is read back through its hash
+const (
+	txByDateIndex         = "txByDate"         // txByDate~date~hash
+	txByBankIndex         = "txByBank"         // txByBank~bankId~date~hash
+	txByRecordedDateIndex = "txByRecordedDate" // txByRecordedDate~createdDate~hash
+	txByBankRecordedIndex = "txByBankRecorded" // txByBankRecorded~bankId~createdDate~hash
+)
+
+// Date fields the transaction queries can filter on: the business date supplied by the
+// client or the date the transaction was recorded on the ledger
+const (
+	DateFieldBusiness = "date"
+	DateFieldRecorded = "created_at"
+)
+
+// dateIndexes returns the date and bank indexes ordered by dateField, empty meaning
+// DateFieldBusiness
+func dateIndexes(dateField string) (string, string, error) {
+	switch dateField {
+	case "", DateFieldBusiness:
+		return txByDateIndex, txByBankIndex, nil
+	case DateFieldRecorded:
+		return txByRecordedDateIndex, txByBankRecordedIndex, nil
+	}
+	return "", "", fmt.Errorf("unknown date field %q, expected %s or %s", dateField, DateFieldBusiness, DateFieldRecorded)
+}
+
+// maxRangeDays bounds the span of a date range query, each day costing one index lookup
+const maxRangeDays = 366
+
+// TransactionRecord a transaction together with the user it is recorded for
+type TransactionRecord struct {
+	UserId string `json:"user_id"`
+	Transaction
+}
+
+// TransactionPage one page of a transaction query. Bookmark is passed back to get the next
+// page and is empty once the query is exhausted
+type TransactionPage struct {
+	Transactions []*TransactionRecord `json:"transactions"`
+	Bookmark     string               `json:"bookmark"`
+}
+
+// indexEntry a composite key index and the attributes of one of its keys
+type indexEntry struct {
+	name       string
+	attributes []string
+}
+
+// transactionIndexKeys returns the date and bank index keys of transaction, by business
+// date and, when it has one, by recorded date
+func transactionIndexKeys(ctx TransactionContextInterface, transaction Transaction) ([]string, error) {
+	indexes := []indexEntry{
+		{txByDateIndex, []string{transaction.Date, transaction.Hash}},
+		{txByBankIndex, []string{transaction.BankId, transaction.Date, transaction.Hash}},
+	}
+	if len(transaction.CreatedAt) >= len("2006-01-02") {
+		createdDate := transaction.CreatedAt[:len("2006-01-02")]
+		indexes = append(indexes,
+			indexEntry{txByRecordedDateIndex, []string{createdDate, transaction.Hash}},
+			indexEntry{txByBankRecordedIndex, []string{transaction.BankId, createdDate, transaction.Hash}},
+		)
+	}
+	var keys []string
+	for _, index := range indexes {
+		key, err := ctx.GetStub().CreateCompositeKey(index.name, index.attributes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", index.name, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// indexTransaction writes the index entries of transaction
+func (s *SmartContract) indexTransaction(ctx TransactionContextInterface, transaction Transaction) error {
+	keys, err := transactionIndexKeys(ctx, transaction)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		err = ctx.GetStub().PutState(key, []byte{0x00})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindexTransaction deletes the date, bank and tag index entries of transaction recorded
+// for userId
+func unindexTransaction(ctx TransactionContextInterface, userId string, transaction Transaction) error {
+	keys, err := transactionIndexKeys(ctx, transaction)
+	if err != nil {
+		return err
+	}
+	tagKeys, err := tagIndexKeys(ctx, userId, transaction)
+	if err != nil {
+		return err
+	}
+	for _, key := range append(keys, tagKeys...) {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTransactionsByDateRange returns the transactions whose dateField, DateFieldBusiness or
+// DateFieldRecorded, falls from startDate to endDate inclusive, pageSize at a time, ordered
+// by that date then hash
+func (s *SmartContract) ListTransactionsByDateRange(ctx TransactionContextInterface, startDate string, endDate string, dateField string, pageSize int, bookmark string) (*TransactionPage, error) {
+	index, _, err := dateIndexes(dateField)
+	if err != nil {
+		return nil, err
+	}
+	return s.listTransactionIndex(ctx, index, nil, startDate, endDate, pageSize, bookmark, true)
+}
+
+// ListTransactionsByBank returns the transactions at bankId whose dateField falls from
+// startDate to endDate inclusive, pageSize at a time, ordered by that date then hash
+func (s *SmartContract) ListTransactionsByBank(ctx TransactionContextInterface, bankId string, startDate string, endDate string, dateField string, pageSize int, bookmark string) (*TransactionPage, error) {
+	_, index, err := dateIndexes(dateField)
+	if err != nil {
+		return nil, err
+	}
+	return s.listTransactionIndex(ctx, index, []string{bankId}, startDate, endDate, pageSize, bookmark, true)
+}
+
+// listTransactionIndex walks index one day at a time, since composite keys cannot be
+// range queried, skipping every key up to and including bookmark and, when checkConsent
+// is set, the transactions of users who gave the caller no consent
+func (s *SmartContract) listTransactionIndex(ctx TransactionContextInterface, index string, attributes []string, startDate string, endDate string, pageSize int, bookmark string, checkConsent bool) (*TransactionPage, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date %q", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date %q", endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date %s is before start date %s", endDate, startDate)
+	}
+	if end.Sub(start) > maxRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("date range must not exceed %d days", maxRangeDays)
+	}
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive")
+	}
+
+	if bookmark != "" {
+		_, bookmarkAttributes, err := ctx.GetStub().SplitCompositeKey(bookmark)
+		if err != nil || len(bookmarkAttributes) != len(attributes)+2 {
+			return nil, fmt.Errorf("invalid bookmark")
+		}
+		bookmarkDate, err := time.Parse("2006-01-02", bookmarkAttributes[len(attributes)])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bookmark")
+		}
+		if bookmarkDate.After(start) {
+			start = bookmarkDate
+		}
+	}
+
+	page := TransactionPage{Transactions: []*TransactionRecord{}}
+	consents := map[string]bool{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		keys, err := s.indexKeys(ctx, index, append(append([]string{}, attributes...), day.Format("2006-01-02")))
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if bookmark != "" && key <= bookmark {
+				continue
+			}
+			_, keyAttributes, err := ctx.GetStub().SplitCompositeKey(key)
+			if err != nil {
+				return nil, err
+			}
+			hash := keyAttributes[len(keyAttributes)-1]
+			user, i, err := s.findTransaction(ctx, hash)
+			if err != nil {
+				return nil, err
+			}
+			if checkConsent {
+				allowed, ok := consents[user.ID]
+				if !ok {
+					allowed, err = s.hasConsent(ctx, user.ID)
+					if err != nil {
+						return nil, err
+					}
+					consents[user.ID] = allowed
+				}
+				if !allowed {
+					continue
+				}
+			}
+			page.Transactions = append(page.Transactions, &TransactionRecord{UserId: user.ID, Transaction: user.Transactions[i]})
+			if len(page.Transactions) == pageSize {
+				page.Bookmark = key
+				return &page, nil
+			}
+		}
+	}
+
+	return &page, nil
+}
+
+// indexKeys returns the keys of index matching the partial attributes, in key order
+func (s *SmartContract) indexKeys(ctx TransactionContextInterface, index string, attributes []string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(index, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var keys []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, queryResponse.Key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,265 @@
+package smartcontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+const TransferPrefix = "Transfer_"
+
+const (
+	EntryDebit  = "debit"
+	EntryCredit = "credit"
+)
+
+// Transfer payment from one registered user to another. It is recorded on both users as a
+// debit and a credit TransferEntry sharing the transfer ID. BankId is the bank of the payer
+// and ToBankId the bank of the payee, they differ for an interbank transfer
+type Transfer struct {
+	ID                string `json:"id"`
+	FromUserId        string `json:"from_user_id"`
+	ToUserId          string `json:"to_user_id"`
+	Amount            string `json:"amount"`
+	Currency          string `json:"currency"`
+	BankId            string `json:"bank_id"`
+	ToBankId          string `json:"to_bank_id"`
+	Reference         string `json:"reference,omitempty" maxLength:"140" metadata:",optional"`
+	CreatedAt         string `json:"created_at"`
+	SettlementBatchID string `json:"settlement_batch_id,omitempty" metadata:",optional"` // set once netted in a SettlementBatch
+}
+
+// TransferEntry one side of a Transfer as seen by the user it is recorded on. Balance is
+// the user's balance in Currency once the entry is applied
+type TransferEntry struct {
+	TransferID   string `json:"transfer_id"`
+	Direction    string `json:"direction"`
+	Counterparty string `json:"counterparty"`
+	Amount       string `json:"amount"`
+	Currency     string `json:"currency"`
+	BankId       string `json:"bank_id"`
+	Reference    string `json:"reference,omitempty" metadata:",optional"`
+	Balance      string `json:"balance"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// Balance of a user in one currency
+type Balance struct {
+	Currency string `json:"currency"`
+	Amount   string `json:"amount"`
+}
+
+// applyEntry records entry on user and moves the running balance of its currency, down
+// for a debit and up for a credit
+func applyEntry(user *User, entry TransferEntry) error {
+	if user.Balances == nil {
+		user.Balances = map[string]string{}
+	}
+	balance, ok := user.Balances[entry.Currency]
+	if !ok {
+		balance = "0"
+	}
+	amount := entry.Amount
+	if entry.Direction == EntryDebit {
+		amount = "-" + amount
+	}
+	balance, err := addAmounts(balance, amount)
+	if err != nil {
+		return err
+	}
+	user.Balances[entry.Currency] = balance
+	entry.Balance = balance
+	user.Transfers = append(user.Transfers, entry)
+	return nil
+}
+
+// TransferBetweenUsers moves amount from fromUserId to toUserId, both banking at bankId, and
+// returns the transfer ID, which is the Fabric transaction ID. Only the payer, through its
+// user_id attribute, or a client of the MSP of bankId may order it. Both users are
+// screened against the watchlist and the amount against the AML rules as for
+// CreateTransaction. Balances are net positions between users and may go negative. A
+// client request ID may be supplied in the transient map under IdempotencyTransientKey to
+// make retries safe
+func (s *SmartContract) TransferBetweenUsers(ctx TransactionContextInterface, fromUserId string, toUserId string, amount string, currency string, bankId string, reference string) (string, error) {
+	return s.transfer(ctx, fromUserId, toUserId, amount, currency, bankId, bankId, reference)
+}
+
+// InterbankTransfer moves amount from fromUserId at fromBankId to toUserId at toBankId,
+// ordered and screened as TransferBetweenUsers. The banks settle what they owe each other
+// through ProposeSettlementBatches
+func (s *SmartContract) InterbankTransfer(ctx TransactionContextInterface, fromUserId string, toUserId string, amount string, currency string, fromBankId string, toBankId string, reference string) (string, error) {
+	return s.transfer(ctx, fromUserId, toUserId, amount, currency, fromBankId, toBankId, reference)
+}
+
+func (s *SmartContract) transfer(ctx TransactionContextInterface, fromUserId string, toUserId string, amount string, currency string, bankId string, toBankId string, reference string) (string, error) {
+	request := []string{fromUserId, toUserId, amount, currency, bankId, toBankId, reference}
+	idempotencyKey, replay, err := idempotentReplay(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	if replay != nil {
+		var transferId string
+		err = json.Unmarshal(replay.Result, &transferId)
+		return transferId, err
+	}
+
+	if fromUserId == toUserId {
+		return "", fmt.Errorf("cannot transfer from user %s to itself", fromUserId)
+	}
+	positive, err := amountExceeds(amount, "0")
+	if err != nil {
+		return "", err
+	}
+	if !positive {
+		return "", fmt.Errorf("transfer amount must be positive")
+	}
+	from, err := s.readUser(ctx, fromUserId)
+	if err != nil {
+		return "", err
+	}
+	to, err := s.readUser(ctx, toUserId)
+	if err != nil {
+		return "", err
+	}
+	for _, user := range []*User{from, to} {
+		err = checkNotErased(user)
+		if err != nil {
+			return "", err
+		}
+	}
+	bank, err := s.GetBankByID(ctx, bankId)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.GetBankByID(ctx, toBankId)
+	if err != nil {
+		return "", err
+	}
+	// the transfer is ordered by the payer or by its bank
+	if requireUser(ctx, fromUserId) != nil {
+		err = requireBankMSP(ctx, bank)
+		if err != nil {
+			return "", err
+		}
+	}
+	now, err := recordedAt(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	transfer := Transfer{
+		ID:         ctx.GetStub().GetTxID(),
+		FromUserId: fromUserId,
+		ToUserId:   toUserId,
+		Amount:     amount,
+		Currency:   currency,
+		BankId:     bankId,
+		ToBankId:   toBankId,
+		Reference:  reference,
+		CreatedAt:  now,
+	}
+	// screened like a transaction of the payer, alerts refer to the transfer ID
+	screened := Transaction{
+		Hash:      transfer.ID,
+		Amount:    amount,
+		Currency:  currency,
+		Date:      now[:len("2006-01-02")],
+		BankId:    bankId,
+		CreatedAt: now,
+	}
+	for _, user := range []*User{from, to} {
+		err = s.screenUser(ctx, user, nil, transfer.ID+"_"+user.ID, &screened)
+		if err != nil {
+			return "", err
+		}
+	}
+	alerts, err := s.screenTransaction(ctx, fromUserId, screened)
+	if err != nil {
+		return "", err
+	}
+	err = emitAMLAlerts(ctx, alerts)
+	if err != nil {
+		return "", err
+	}
+
+	sides := []struct {
+		user         *User
+		direction    string
+		counterparty string
+		bankId       string
+	}{
+		{from, EntryDebit, toUserId, bankId},
+		{to, EntryCredit, fromUserId, toBankId},
+	}
+	for _, side := range sides {
+		err = applyEntry(side.user, TransferEntry{
+			TransferID:   transfer.ID,
+			Direction:    side.direction,
+			Counterparty: side.counterparty,
+			Amount:       amount,
+			Currency:     currency,
+			BankId:       side.bankId,
+			Reference:    reference,
+			CreatedAt:    now,
+		})
+		if err != nil {
+			return "", err
+		}
+		side.user.UpdatedAt = now
+		err = ctx.PutStateJSON(side.user.ID, side.user)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = ctx.PutStateJSON(TransferPrefix+transfer.ID, transfer)
+	if err != nil {
+		return "", err
+	}
+	err = recordIdempotentResult(ctx, idempotencyKey, request, transfer.ID)
+	if err != nil {
+		return "", err
+	}
+	return transfer.ID, nil
+}
+
+// GetTransfer returns the transfer recorded under transferId. It is part of the data of
+// both users, the consent of either of them suffices
+func (s *SmartContract) GetTransfer(ctx TransactionContextInterface, transferId string) (*Transfer, error) {
+	var transfer Transfer
+	exists, err := ctx.GetStateJSON(TransferPrefix+transferId, &transfer)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("the transfer %s does not exist", transferId)
+	}
+	allowed, err := s.hasConsent(ctx, transfer.FromUserId)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		err = s.requireConsent(ctx, transfer.ToUserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &transfer, nil
+}
+
+// GetUserBalances returns the running balances of userId, one per currency it has
+// transferred in, sorted by currency
+func (s *SmartContract) GetUserBalances(ctx TransactionContextInterface, userId string) ([]*Balance, error) {
+	user, err := s.GetUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	balances := []*Balance{}
+	for currency, amount := range user.Balances {
+		balances = append(balances, &Balance{Currency: currency, Amount: amount})
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Currency < balances[j].Currency
+	})
+	return balances, nil
+}
@@ -0,0 +1,82 @@
+package smartcontract
+
+import (
+	"fmt"
+)
+
+// UserMSPPrefix starts the keys of the MSPs trusted to issue user identities
+const UserMSPPrefix = "UserMSP_"
+
+// AddUserMSP trusts the CA of mspId to issue identities carrying the user_id attribute.
+// Any CA can put any attribute in the certificates it issues, so the attribute is ignored
+// in identities of other MSPs. An MSP bound to a bank cannot be trusted, its CA could
+// issue identities acting for the bank's customers. Admin only
+func (s *SmartContract) AddUserMSP(ctx TransactionContextInterface, mspId string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if mspId == "" {
+		return fmt.Errorf("MSP ID must not be empty")
+	}
+	bankId, err := ctx.GetStub().GetState(bankMSPPrefix + mspId)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if bankId != nil {
+		return fmt.Errorf("the MSP %s belongs to bank %s", mspId, bankId)
+	}
+	return ctx.GetStub().PutState(UserMSPPrefix+mspId, []byte(mspId))
+}
+
+// RemoveUserMSP stops trusting the user_id attribute in identities of mspId. Admin only
+func (s *SmartContract) RemoveUserMSP(ctx TransactionContextInterface, mspId string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	trusted, err := isUserMSP(ctx, mspId)
+	if err != nil {
+		return err
+	}
+	if !trusted {
+		return fmt.Errorf("the MSP %s is not trusted to issue user identities", mspId)
+	}
+	return ctx.GetStub().DelState(UserMSPPrefix + mspId)
+}
+
+func isUserMSP(ctx TransactionContextInterface, mspId string) (bool, error) {
+	trusted, err := ctx.GetStub().GetState(UserMSPPrefix + mspId)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return trusted != nil, nil
+}
+
+// callerUserID returns the user the caller acts for, the user_id attribute of its identity
+// when the identity comes from the admin organization or an MSP added with AddUserMSP
+func callerUserID(ctx TransactionContextInterface) (string, bool, error) {
+	mspID, err := ctx.GetCallerMSPID()
+	if err != nil {
+		return "", false, err
+	}
+	if mspID != AdminMSPID {
+		trusted, err := isUserMSP(ctx, mspID)
+		if err != nil || !trusted {
+			return "", false, err
+		}
+	}
+	return ctx.GetCallerAttribute(UserIDAttribute)
+}
+
+// requireUser rejects callers not acting for userId
+func requireUser(ctx TransactionContextInterface, userId string) error {
+	callerUserId, found, err := callerUserID(ctx)
+	if err != nil {
+		return err
+	}
+	if !found || callerUserId != userId {
+		return fmt.Errorf("client is not authorized to act for user %s", userId)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package smartcontract
+
+//go:generate go run ../cmd/metadatagen -metadata ../contract-metadata/metadata.json -bindings parameters_generated.go -embedded metadata_generated.go
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+)
+
+// fieldRule holds the JSON schema style constraints read from the pattern, minLength,
+// maxLength and format tags of a struct field
+type fieldRule struct {
+	Name      string
+	Pattern   *regexp.Regexp
+	MinLength int
+	MaxLength int
+	Format    string
+}
+
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// validationRules maps "Struct.Field" to the rule declared on that field
+var validationRules = map[string]fieldRule{}
+
+// parameterNames binds transaction parameters, by name, to the struct field whose rule
+// they must satisfy. "Function.parameter" entries take precedence over plain names, an
+// entry of "" leaves the parameter unchecked. parameterFields and the parameter schemas of
+// contract-metadata/metadata.json are generated from it and the function signatures, run
+// go generate after changing either
+var parameterNames = map[string]string{
+	"id":                          "User.ID",
+	"userId":                      "User.ID",
+	"fromUserId":                  "User.ID",
+	"toUserId":                    "User.ID",
+	"prefix":                      "PersonalData.Name",
+	"hash":                        "Transaction.Hash",
+	"amount":                      "Transaction.Amount",
+	"currency":                    "Transaction.Currency",
+	"date":                        "Transaction.Date",
+	"startDate":                   "Transaction.Date",
+	"endDate":                     "Transaction.Date",
+	"beforeDate":                  "Transaction.Date",
+	"asOf":                        "Transaction.Date",
+	"windowStart":                 "Transaction.Date",
+	"windowEnd":                   "Transaction.Date",
+	"bankId":                      "Bank.ID",
+	"fromBankId":                  "Bank.ID",
+	"toBankId":                    "Bank.ID",
+	"reference":                   "Transfer.Reference",
+	"reason":                      "Dispute.Reason",
+	"outcome":                     "Dispute.Outcome",
+	"mspId":                       "Consent.MSPID",
+	"expiresOn":                   "Consent.ExpiresOn",
+	"schedule":                    "StandingOrder.Schedule",
+	"CreateTransaction.reference": "Transaction.Reference",
+	"RespondDispute.response":     "DisputeEvent.Note",
+	"ResolveDispute.note":         "DisputeEvent.Note",
+	"AddWatchlistEntry.id":        "WatchlistEntry.ID",
+	"AddWatchlistEntry.name":      "WatchlistEntry.Name",
+	"AddWatchlistEntry.action":    "WatchlistEntry.Action",
+	"RemoveWatchlistEntry.id":     "WatchlistEntry.ID",
+	"ScreenName.name":             "WatchlistEntry.Name",
+	"ResolveAlert.id":             "",
+	"GetStandingOrder.id":         "",
+	"CancelStandingOrder.id":      "",
+	"GetArchiveSummary.id":        "",
+}
+
+// ParameterField returns the "Struct.Field" whose rule the parameter of function must
+// satisfy, "" when it is not checked
+func ParameterField(function string, parameter string) string {
+	if field, ok := parameterNames[function+"."+parameter]; ok {
+		return field
+	}
+	return parameterNames[parameter]
+}
+
+func init() {
+	for _, value := range []interface{}{User{}, Transaction{}, Bank{}, Transfer{}, Dispute{}, DisputeEvent{}, Consent{}, WatchlistEntry{}, StandingOrder{}, PersonalData{}} {
+		registerRules(reflect.TypeOf(value))
+	}
+}
+
+func registerRules(structType reflect.Type) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		rule := fieldRule{Name: strings.Split(field.Tag.Get("json"), ",")[0], Format: field.Tag.Get("format")}
+		if pattern := field.Tag.Get("pattern"); pattern != "" {
+			rule.Pattern = regexp.MustCompile(pattern)
+		}
+		if minLength := field.Tag.Get("minLength"); minLength != "" {
+			rule.MinLength, _ = strconv.Atoi(minLength)
+		}
+		if maxLength := field.Tag.Get("maxLength"); maxLength != "" {
+			rule.MaxLength, _ = strconv.Atoi(maxLength)
+		}
+		if rule.Pattern != nil || rule.MinLength > 0 || rule.MaxLength > 0 || rule.Format != "" {
+			validationRules[structType.Name()+"."+field.Name] = rule
+		}
+	}
+}
+
+// check returns an error describing the first constraint value breaks
+func (rule fieldRule) check(value string) error {
+	length := utf8.RuneCountInString(value)
+	if length < rule.MinLength {
+		return fmt.Errorf("%s must be at least %d characters", rule.Name, rule.MinLength)
+	}
+	if rule.MaxLength > 0 && length > rule.MaxLength {
+		return fmt.Errorf("%s must be at most %d characters", rule.Name, rule.MaxLength)
+	}
+	if rule.Pattern != nil && !rule.Pattern.MatchString(value) {
+		return fmt.Errorf("%s %q does not match pattern %s", rule.Name, value, rule.Pattern)
+	}
+	switch rule.Format {
+	case "email":
+		if !emailPattern.MatchString(value) {
+			return fmt.Errorf("%s %q is not a valid email address", rule.Name, value)
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("%s %q is not a valid date", rule.Name, value)
+		}
+	}
+	return nil
+}
+
+// ApplyParameterRule adds the rule of field to the JSON schema of a string parameter in
+// the contract metadata, where contractapi enforces it too
+func ApplyParameterRule(parameter *metadata.ParameterMetadata, field string) {
+	rule, ok := validationRules[field]
+	if !ok || parameter.Schema == nil {
+		return
+	}
+	if rule.Pattern != nil {
+		parameter.Schema.Pattern = rule.Pattern.String()
+	}
+	if rule.MinLength > 0 {
+		minLength := int64(rule.MinLength)
+		parameter.Schema.MinLength = &minLength
+	}
+	if rule.MaxLength > 0 {
+		maxLength := int64(rule.MaxLength)
+		parameter.Schema.MaxLength = &maxLength
+	}
+	parameter.Schema.Format = rule.Format
+}
+
+// validateParameters runs before every transaction and rejects parameters which break
+// the rule of the field they are bound to in parameterFields. contractapi checks the same
+// rules when contract-metadata/metadata.json is deployed next to the chaincode binary, this
+// keeps them enforced when it is not
+func (s *SmartContract) validateParameters(ctx TransactionContextInterface) error {
+	function, params := ctx.GetStub().GetFunctionAndParameters()
+	if i := strings.LastIndex(function, ":"); i >= 0 {
+		function = function[i+1:]
+	}
+
+	for i, field := range parameterFields[function] {
+		if i >= len(params) {
+			break
+		}
+		err := validationRules[field].check(params[i])
+		if err != nil {
+			return fmt.Errorf("invalid %s parameter: %v", function, err)
+		}
+	}
+	return nil
+}
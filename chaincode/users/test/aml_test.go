@@ -0,0 +1,110 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SetAMLRuleRequiresAdmin(t *testing.T) {
+	fmt.Println("Test_SetAMLRuleRequiresAdmin-----------------")
+	NewStub()
+
+	MockSetCreator("Org2MSP", "teller")
+	assert.NotNil(t, MockSetAMLRule("USD", "1000", "1500"))
+
+	MockSetCreator("Org1MSP", "compliance")
+	assert.Nil(t, MockSetAMLRule("USD", "1000", "1500"))
+}
+
+func Test_CreateTransactionRaisesAlerts(t *testing.T) {
+	fmt.Println("Test_CreateTransactionRaisesAlerts-----------------")
+	NewStub()
+	MockSetCreator("Org1MSP", "compliance")
+
+	err := MockSetAMLRule("USD", "1000", "1500")
+	if err != nil {
+		t.FailNow()
+	}
+	err = MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+
+	small := smartcontract.Transaction{Amount: "999", Currency: "USD", Date: "2022-04-14", BankId: transaction1.BankId}
+	large := smartcontract.Transaction{Amount: "1200", Currency: "USD", Date: "2022-04-14", BankId: transaction1.BankId}
+	other := smartcontract.Transaction{Amount: "100", Currency: "NTD", Date: "2022-04-14", BankId: transaction1.BankId}
+	largeHash := TxHash(user1.ID, large)
+
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, small), small.Amount, small.Currency, small.Date, small.BankId)
+	assert.Nil(t, err)
+	alerts, err := MockListAlerts(smartcontract.AlertStatusOpen)
+	assert.Nil(t, err)
+	assert.Equal(t, len(alerts), 0)
+
+	_, err = MockCreateTransaction(user1.ID, largeHash, large.Amount, large.Currency, large.Date, large.BankId)
+	assert.Nil(t, err)
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, other), other.Amount, other.Currency, other.Date, other.BankId)
+	assert.Nil(t, err)
+
+	alerts, err = MockListAlerts(smartcontract.AlertStatusOpen)
+	assert.Nil(t, err)
+	assert.Equal(t, len(alerts), 2)
+	assert.Equal(t, alerts[0].ID, largeHash+"_daily_limit")
+	assert.Equal(t, alerts[0].Amount, "2199")
+	assert.Equal(t, alerts[1].ID, largeHash+"_threshold")
+
+	err = MockResolveAlert(largeHash+"_threshold", "salary bonus, documented")
+	assert.Nil(t, err)
+	assert.NotNil(t, MockResolveAlert(largeHash+"_threshold", "again"))
+
+	alerts, err = MockListAlerts(smartcontract.AlertStatusResolved)
+	assert.Nil(t, err)
+	assert.Equal(t, len(alerts), 1)
+	assert.Equal(t, alerts[0].Note, "salary bonus, documented")
+
+	MockSetCreator("Org2MSP", "teller")
+	_, err = MockListAlerts("")
+	assert.NotNil(t, err)
+}
+
+func MockSetAMLRule(currency string, threshold string, dailyLimit string) error {
+	res := Stub.MockInvoke("uuid",
+		[][]byte{
+			[]byte("SetAMLRule"),
+			[]byte(currency),
+			[]byte(threshold),
+			[]byte(dailyLimit),
+		})
+	if res.Status != shim.OK {
+		fmt.Println("SetAMLRule failed", string(res.Message))
+		return errors.New("SetAMLRule error")
+	}
+	return nil
+}
+
+func MockListAlerts(status string) ([]*smartcontract.Alert, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ListAlerts"), []byte(status)})
+	if res.Status != shim.OK {
+		fmt.Println("ListAlerts failed", string(res.Message))
+		return nil, errors.New("ListAlerts error")
+	}
+	var alerts []*smartcontract.Alert
+	json.Unmarshal(res.Payload, &alerts)
+	return alerts, nil
+}
+
+func MockResolveAlert(id string, note string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ResolveAlert"), []byte(id), []byte(note)})
+	if res.Status != shim.OK {
+		fmt.Println("ResolveAlert failed", string(res.Message))
+		return errors.New("ResolveAlert error")
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ArchiveTransactions(t *testing.T) {
+	fmt.Println("Test_ArchiveTransactions-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	transactions := []smartcontract.Transaction{
+		{Amount: "50", Currency: "USD", Date: "2022-03-31", BankId: "04231910"},
+		{Amount: "200", Currency: "USD", Date: "2022-04-14", BankId: "04231910"},
+		{Amount: "500", Currency: "NTD", Date: "2022-04-02", BankId: "03750168"},
+		{Amount: "70", Currency: "NTD", Date: "2022-05-01", BankId: "04231910"},
+	}
+	var hashes []string
+	for _, transaction := range transactions {
+		hash := TxHash(user1.ID, transaction)
+		_, err := MockCreateTransaction(user1.ID, hash, transaction.Amount, transaction.Currency, transaction.Date, transaction.BankId)
+		if err != nil {
+			t.FailNow()
+		}
+		hashes = append(hashes, hash)
+	}
+	signingKey, publicKey := NewFieldSigningKey()
+	otherKey, otherPublicKey := NewFieldSigningKey()
+
+	_, err := MockArchiveTransactions(user1.ID, "2022-04-15", signingKey)
+	assert.NotNil(t, err)
+	MockSetCreator("Org2MSP", "teller")
+	assert.NotNil(t, MockRegisterArchiveKey(string(publicKey)))
+	MockSetCreator("Org1MSP", "admin")
+	assert.NotNil(t, MockRegisterArchiveKey("not a key"))
+	assert.Nil(t, MockRegisterArchiveKey(string(publicKey)))
+
+	_, err = MockArchiveTransactions(user1.ID, "2022-04-15", nil)
+	assert.NotNil(t, err)
+	_, err = MockArchiveTransactions(user1.ID, "2022-04-15", otherKey)
+	assert.NotNil(t, err)
+	_, err = MockArchiveTransactions(user1.ID, "2022-01-01", signingKey)
+	assert.NotNil(t, err)
+	result, err := MockArchiveTransactions(user1.ID, "2022-04-15", signingKey)
+	assert.Nil(t, err)
+	assert.Equal(t, result.Summary.Count, 3)
+	assert.Equal(t, result.Summary.KeyVersion, 1)
+	assert.Equal(t, result.Summary.Totals, map[string]string{"USD": "250", "NTD": "500"})
+	assert.Equal(t, len(result.Transactions), 3)
+	assert.Equal(t, result.Transactions[2].Hash, hashes[2])
+
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 1)
+	assert.Equal(t, user.Archives, []string{result.Summary.ID})
+	page, err := MockListTransactionsByDateRange("2022-03-01", "2022-05-31", smartcontract.DateFieldBusiness, 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Transactions), 1)
+	summary, err := MockGetArchiveSummary(result.Summary.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, summary.MerkleRoot, result.Summary.MerkleRoot)
+
+	for _, archived := range result.Transactions {
+		proof, _ := json.Marshal(archived.Proof)
+		verified, err := MockVerifyArchivedTransaction(archived.Hash, string(proof))
+		assert.Nil(t, err)
+		assert.True(t, verified)
+	}
+	// a later key version leaves the archives signed with earlier ones verifiable
+	assert.Nil(t, MockRegisterArchiveKey(string(otherPublicKey)))
+	key, err := MockGetArchiveKey(2)
+	assert.Nil(t, err)
+	assert.Equal(t, key.PublicKey, string(otherPublicKey))
+	proof, _ := json.Marshal(result.Transactions[0].Proof)
+	verified, err := MockVerifyArchivedTransaction(result.Transactions[0].Hash, string(proof))
+	assert.Nil(t, err)
+	assert.True(t, verified)
+	verified, err = MockVerifyArchivedTransaction(hashes[1], string(proof))
+	assert.Nil(t, err)
+	assert.False(t, verified)
+	_, err = MockVerifyArchivedTransaction(hashes[3], string(proof))
+	assert.NotNil(t, err)
+
+	_, err = MockCreateTransaction(user1.ID, hashes[0], transactions[0].Amount, transactions[0].Currency, transactions[0].Date, transactions[0].BankId)
+	assert.NotNil(t, err)
+}
+
+func MockRegisterArchiveKey(publicKey string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("RegisterArchiveKey"), []byte(publicKey)})
+	if res.Status != shim.OK {
+		fmt.Println("RegisterArchiveKey failed", string(res.Message))
+		return errors.New("RegisterArchiveKey error")
+	}
+	return nil
+}
+
+func MockGetArchiveKey(version int) (*smartcontract.ArchiveKey, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetArchiveKey"), []byte(strconv.Itoa(version))})
+	if res.Status != shim.OK {
+		fmt.Println("GetArchiveKey failed", string(res.Message))
+		return nil, errors.New("GetArchiveKey error")
+	}
+	var key smartcontract.ArchiveKey
+	json.Unmarshal(res.Payload, &key)
+	return &key, nil
+}
+
+func MockArchiveTransactions(userId string, beforeDate string, signingKey []byte) (*smartcontract.ArchiveResult, error) {
+	transient := map[string][]byte{}
+	if signingKey != nil {
+		transient[smartcontract.SigningKeyTransientKey] = signingKey
+	}
+	res := MockInvokeWithTransient("uuid", [][]byte{[]byte("ArchiveTransactions"), []byte(userId), []byte(beforeDate)}, transient)
+	if res.Status != shim.OK {
+		fmt.Println("ArchiveTransactions failed", string(res.Message))
+		return nil, errors.New("ArchiveTransactions error")
+	}
+	var result smartcontract.ArchiveResult
+	json.Unmarshal(res.Payload, &result)
+	return &result, nil
+}
+
+func MockGetArchiveSummary(id string) (*smartcontract.ArchiveSummary, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetArchiveSummary"), []byte(id)})
+	if res.Status != shim.OK {
+		fmt.Println("GetArchiveSummary failed", string(res.Message))
+		return nil, errors.New("GetArchiveSummary error")
+	}
+	var summary smartcontract.ArchiveSummary
+	json.Unmarshal(res.Payload, &summary)
+	return &summary, nil
+}
+
+func MockVerifyArchivedTransaction(hash string, proof string) (bool, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("VerifyArchivedTransaction"), []byte(hash), []byte(proof)})
+	if res.Status != shim.OK {
+		fmt.Println("VerifyArchivedTransaction failed", string(res.Message))
+		return false, errors.New("VerifyArchivedTransaction error")
+	}
+	return string(res.Payload) == "true", nil
+}
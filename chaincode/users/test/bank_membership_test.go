@@ -0,0 +1,107 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BankMembership(t *testing.T) {
+	fmt.Println("Test_BankMembership-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	cathay := smartcontract.Transaction{Amount: "200", Currency: "USD", Date: "2022-04-14", BankId: "04231910"}
+	fubon := smartcontract.Transaction{Amount: "50", Currency: "USD", Date: "2022-04-14", BankId: "03750168"}
+
+	MockSetCreator("Org2MSP", "bank")
+	assert.NotNil(t, MockSetBankMSPID("04231910", "Org2MSP"))
+	MockSetCreator("Org1MSP", "admin")
+	assert.Nil(t, MockSetBankMSPID("04231910", "Org2MSP"))
+	assert.NotNil(t, MockSetBankMSPID("03750168", "Org2MSP"))
+	bank, err := MockGetBankByID("04231910")
+	assert.Nil(t, err)
+	assert.Equal(t, bank.MSPID, "Org2MSP")
+
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, cathay), cathay.Amount, cathay.Currency, cathay.Date, cathay.BankId)
+	assert.NotNil(t, err)
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, fubon), fubon.Amount, fubon.Currency, fubon.Date, fubon.BankId)
+	assert.Nil(t, err)
+	MockSetCreator("Org2MSP", "bank")
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, cathay), cathay.Amount, cathay.Currency, cathay.Date, cathay.BankId)
+	assert.Nil(t, err)
+
+	page, err := MockListMyBankTransactions("2022-04-01", "2022-04-30", smartcontract.DateFieldBusiness, 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Transactions), 1)
+	assert.Equal(t, page.Transactions[0].BankId, "04231910")
+	assert.Equal(t, page.Transactions[0].UserId, user1.ID)
+
+	MockSetCreator("Org3MSP", "someone")
+	_, err = MockListMyBankTransactions("2022-04-01", "2022-04-30", smartcontract.DateFieldBusiness, 10, "")
+	assert.NotNil(t, err)
+
+	MockSetCreator("Org1MSP", "admin")
+	assert.Nil(t, MockSetBankMSPID("04231910", "Org3MSP"))
+	assert.Nil(t, MockSetBankMSPID("03750168", "Org2MSP"))
+}
+
+func Test_UnboundBankActsThroughAdmin(t *testing.T) {
+	fmt.Println("Test_UnboundBankActsThroughAdmin-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	hash := TxHash(user1.ID, transaction1)
+
+	MockSetCreator("Org2MSP", "bank")
+	_, err := MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.NotNil(t, err)
+	_, err = MockCreateStandingOrder("order1", user1.ID, "100", "USD", transaction1.BankId, smartcontract.ScheduleDaily)
+	assert.NotNil(t, err)
+
+	MockSetCreator("Org1MSP", "admin")
+	_, err = MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.Nil(t, err)
+	assert.Nil(t, MockAddUserMSP("Org3MSP"))
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	assert.Nil(t, MockOpenDispute(hash, "charged twice"))
+
+	MockSetCreator("Org2MSP", "bank")
+	assert.NotNil(t, MockRespondDispute(hash, "looking into it"))
+	assert.NotNil(t, MockTagTransaction(hash, "monthly"))
+	MockSetCreator("Org1MSP", "admin")
+	assert.Nil(t, MockRespondDispute(hash, "looking into it"))
+}
+
+func MockSetBankMSPID(bankId string, mspId string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("SetBankMSPID"), []byte(bankId), []byte(mspId)})
+	if res.Status != shim.OK {
+		fmt.Println("SetBankMSPID failed", string(res.Message))
+		return errors.New("SetBankMSPID error")
+	}
+	return nil
+}
+
+func MockListMyBankTransactions(startDate string, endDate string, dateField string, pageSize int, bookmark string) (*smartcontract.TransactionPage, error) {
+	res := Stub.MockInvoke("uuid",
+		[][]byte{
+			[]byte("ListMyBankTransactions"),
+			[]byte(startDate),
+			[]byte(endDate),
+			[]byte(dateField),
+			[]byte(strconv.Itoa(pageSize)),
+			[]byte(bookmark),
+		})
+	if res.Status != shim.OK {
+		fmt.Println("ListMyBankTransactions failed", string(res.Message))
+		return nil, errors.New("ListMyBankTransactions error")
+	}
+	var page smartcontract.TransactionPage
+	json.Unmarshal(res.Payload, &page)
+	return &page, nil
+}
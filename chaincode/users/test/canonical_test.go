@@ -0,0 +1,144 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+// TxHash returns the hash CreateTransaction expects for transaction recorded for userId
+func TxHash(userId string, transaction smartcontract.Transaction) string {
+	hash, err := smartcontract.TransactionHash(userId, transaction)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+func Test_CanonicalTransaction(t *testing.T) {
+	fmt.Println("Test_CanonicalTransaction-----------------")
+	payload, err := smartcontract.CanonicalTransaction(user1.ID, transaction1)
+	assert.Nil(t, err)
+	assert.Equal(t, string(payload), `{"amount":"200","bank_id":"04231910","currency":"USD","date":"2022-04-14","user_id":"1"}`)
+
+	padded := transaction1
+	padded.Amount = "200.00"
+	assert.Equal(t, TxHash(user1.ID, padded), TxHash(user1.ID, transaction1))
+	assert.NotEqual(t, TxHash(user2.ID, transaction1), TxHash(user1.ID, transaction1))
+}
+
+func Test_CreateTransactionChecksHash(t *testing.T) {
+	fmt.Println("Test_CreateTransactionChecksHash-----------------")
+	NewStub()
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction2), transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.NotNil(t, err)
+
+	hash := "0x" + TxHash(user1.ID, transaction1)
+	_, err = MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.Nil(t, err)
+	_, err = MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.NotNil(t, err)
+
+	valid, err := MockVerifyTransaction(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, valid, true)
+
+	_, err = MockVerifyTransaction(TxHash(user1.ID, transaction2))
+	assert.NotNil(t, err)
+}
+
+func Test_CreateTransactionNormalizesHash(t *testing.T) {
+	fmt.Println("Test_CreateTransactionNormalizesHash-----------------")
+	NewStub()
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+
+	hash := TxHash(user1.ID, transaction1)
+	_, err = MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.Nil(t, err)
+	_, err = MockCreateTransaction(user1.ID, "0x"+strings.ToUpper(hash), transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.NotNil(t, err)
+
+	user, err := MockGetUserByTransactionHash("0x" + strings.ToUpper(hash))
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 1)
+	assert.Equal(t, user.Transactions[0].Hash, hash)
+	bank, err := MockGetBankByID(transaction1.BankId)
+	assert.Nil(t, err)
+	assert.Equal(t, bank.TransactionCount, 1)
+}
+
+func Test_CreateTransactionReference(t *testing.T) {
+	fmt.Println("Test_CreateTransactionReference-----------------")
+	NewStub()
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+
+	first := transaction1
+	first.Reference = "INV-1"
+	second := transaction1
+	second.Reference = "INV-2"
+	assert.NotEqual(t, TxHash(user1.ID, first), TxHash(user1.ID, second))
+
+	_, err = MockCreateReferencedTransaction(user1.ID, first)
+	assert.Nil(t, err)
+	_, err = MockCreateReferencedTransaction(user1.ID, second)
+	assert.Nil(t, err)
+	_, err = MockCreateReferencedTransaction(user1.ID, second)
+	assert.NotNil(t, err)
+
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 2)
+	assert.Equal(t, user.Transactions[1].Reference, "INV-2")
+	valid, err := MockVerifyTransaction(TxHash(user1.ID, second))
+	assert.Nil(t, err)
+	assert.Equal(t, valid, true)
+}
+
+func MockCreateReferencedTransaction(userId string, transaction smartcontract.Transaction) (string, error) {
+	res := Stub.MockInvoke("uuid",
+		[][]byte{
+			[]byte("CreateTransaction"),
+			[]byte(userId),
+			[]byte(TxHash(userId, transaction)),
+			[]byte(transaction.Amount),
+			[]byte(transaction.Currency),
+			[]byte(transaction.Date),
+			[]byte(transaction.BankId),
+			[]byte(transaction.Reference),
+			[]byte(BankSignature(userId, transaction)),
+		})
+	if res.Status != shim.OK {
+		fmt.Println("CreateTransaction failed", string(res.Message))
+		return "", errors.New("CreateTransaction error")
+	}
+	return string(res.Payload), nil
+}
+
+func MockVerifyTransaction(hash string) (bool, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("VerifyTransaction"), []byte(hash)})
+	if res.Status != shim.OK {
+		fmt.Println("VerifyTransaction failed", string(res.Message))
+		return false, errors.New("VerifyTransaction error")
+	}
+	var result bool = false
+	json.Unmarshal(res.Payload, &result)
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ConsentGatesBankReads(t *testing.T) {
+	fmt.Println("Test_ConsentGatesBankReads-----------------")
+	NewStub()
+	MockAddUserMSP("Org3MSP")
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	hash := TxHash(user1.ID, transaction1)
+	_, err := MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	if err != nil {
+		t.FailNow()
+	}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+
+	MockSetCreator("Org2MSP", "bank")
+	_, err = MockGetUser(user1.ID)
+	assert.NotNil(t, err)
+	_, err = MockListUserTransactions(user1.ID)
+	assert.NotNil(t, err)
+	_, err = MockGetUserByTransactionHash(hash)
+	assert.NotNil(t, err)
+	users, err := MockGetAllUsers()
+	assert.Nil(t, err)
+	assert.Equal(t, len(users), 0)
+	assert.NotNil(t, MockGrantConsent(user1.ID, "Org2MSP", tomorrow))
+
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	transactions, err := MockListUserTransactions(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(transactions), 1)
+	assert.NotNil(t, MockGrantConsent(user1.ID, "Org2MSP", yesterday))
+	assert.Nil(t, MockGrantConsent(user1.ID, "Org2MSP", tomorrow))
+
+	MockSetCreator("Org2MSP", "bank")
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, user.ID, user1.ID)
+	transactions, err = MockListUserTransactions(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, transactions[0].Hash, hash)
+	users, err = MockGetAllUsers()
+	assert.Nil(t, err)
+	assert.Equal(t, len(users), 1)
+	assert.NotNil(t, MockRevokeConsent(user1.ID, "Org2MSP"))
+
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	assert.Nil(t, MockRevokeConsent(user1.ID, "Org2MSP"))
+	consents, err := MockListUserConsents(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(consents), 1)
+	assert.Equal(t, consents[0].Status, smartcontract.ConsentRevoked)
+
+	MockSetCreator("Org2MSP", "bank")
+	_, err = MockGetUser(user1.ID)
+	assert.NotNil(t, err)
+}
+
+func Test_DeleteUserConsents(t *testing.T) {
+	fmt.Println("Test_DeleteUserConsents-----------------")
+	NewStub()
+	MockAddUserMSP("Org3MSP")
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	if MockGrantConsent(user1.ID, "Org2MSP", tomorrow) != nil {
+		t.FailNow()
+	}
+
+	MockSetCreator("Org1MSP", "admin")
+	assert.Nil(t, MockDeleteUser(user1.ID))
+	assert.Nil(t, MockCreateUser(user1.ID, "Someone Else", "someone@gmail.com"))
+
+	// the consent of the deleted user does not carry over to a new user under its ID
+	MockSetCreator("Org2MSP", "bank")
+	_, err := MockGetUser(user1.ID)
+	assert.NotNil(t, err)
+	MockSetCreator("Org1MSP", "admin")
+	consents, err := MockListUserConsents(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(consents), 0)
+}
+
+func MockGrantConsent(userId string, mspId string, expiresOn string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GrantConsent"), []byte(userId), []byte(mspId), []byte(expiresOn)})
+	if res.Status != shim.OK {
+		fmt.Println("GrantConsent failed", string(res.Message))
+		return errors.New("GrantConsent error")
+	}
+	return nil
+}
+
+func MockRevokeConsent(userId string, mspId string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("RevokeConsent"), []byte(userId), []byte(mspId)})
+	if res.Status != shim.OK {
+		fmt.Println("RevokeConsent failed", string(res.Message))
+		return errors.New("RevokeConsent error")
+	}
+	return nil
+}
+
+func MockListUserConsents(userId string) ([]*smartcontract.Consent, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ListUserConsents"), []byte(userId)})
+	if res.Status != shim.OK {
+		fmt.Println("ListUserConsents failed", string(res.Message))
+		return nil, errors.New("ListUserConsents error")
+	}
+	var consents []*smartcontract.Consent
+	json.Unmarshal(res.Payload, &consents)
+	return consents, nil
+}
+
+func MockListUserTransactions(userId string) ([]smartcontract.Transaction, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ListUserTransactions"), []byte(userId)})
+	if res.Status != shim.OK {
+		fmt.Println("ListUserTransactions failed", string(res.Message))
+		return nil, errors.New("ListUserTransactions error")
+	}
+	var transactions []smartcontract.Transaction
+	json.Unmarshal(res.Payload, &transactions)
+	return transactions, nil
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DisputeWorkflow(t *testing.T) {
+	fmt.Println("Test_DisputeWorkflow-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	hash := TxHash(user1.ID, transaction1)
+	_, err := MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	if err != nil {
+		t.FailNow()
+	}
+
+	err = MockSetBankMSPID(transaction1.BankId, "Org2MSP")
+	if err != nil {
+		t.FailNow()
+	}
+
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	assert.NotNil(t, MockOpenDispute(hash, "untrusted MSP"))
+	MockSetCreator("Org1MSP", "admin")
+	assert.Nil(t, MockAddUserMSP("Org3MSP"))
+	MockSetCreatorWithAttributes("Org3MSP", "someone", map[string]string{smartcontract.UserIDAttribute: user2.ID})
+	assert.NotNil(t, MockOpenDispute(hash, "not mine"))
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	assert.Nil(t, MockOpenDispute(hash, "charged twice"))
+	assert.NotNil(t, MockOpenDispute(hash, "charged twice"))
+	assert.NotNil(t, MockRespondDispute(hash, "the owner cannot respond"))
+
+	MockSetCreator("Org4MSP", "other bank")
+	assert.NotNil(t, MockRespondDispute(hash, "not our transaction"))
+	_, err = MockGetDispute(hash)
+	assert.NotNil(t, err)
+	MockSetCreator("Org2MSP", "bank")
+	assert.Nil(t, MockRespondDispute(hash, "looking into it"))
+	assert.NotNil(t, MockRespondDispute(hash, "again"))
+	assert.NotNil(t, MockResolveDispute(hash, "refunded", ""))
+	assert.Nil(t, MockResolveDispute(hash, smartcontract.DisputeUpheld, "refunded"))
+	assert.NotNil(t, MockResolveDispute(hash, smartcontract.DisputeRejected, ""))
+
+	dispute, err := MockGetDispute(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, dispute.UserId, user1.ID)
+	assert.Equal(t, dispute.BankId, transaction1.BankId)
+	assert.Equal(t, dispute.Status, smartcontract.DisputeResolved)
+	assert.Equal(t, dispute.Outcome, smartcontract.DisputeUpheld)
+	assert.Equal(t, len(dispute.Timeline), 3)
+	assert.Equal(t, dispute.Timeline[0].Note, "charged twice")
+	assert.Equal(t, dispute.Timeline[1].Status, smartcontract.DisputeResponded)
+	assert.NotEqual(t, dispute.Timeline[2].Actor, dispute.Timeline[0].Actor)
+}
+
+func MockOpenDispute(hash string, reason string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("OpenDispute"), []byte(hash), []byte(reason)})
+	if res.Status != shim.OK {
+		fmt.Println("OpenDispute failed", string(res.Message))
+		return errors.New("OpenDispute error")
+	}
+	return nil
+}
+
+func MockRespondDispute(hash string, response string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("RespondDispute"), []byte(hash), []byte(response)})
+	if res.Status != shim.OK {
+		fmt.Println("RespondDispute failed", string(res.Message))
+		return errors.New("RespondDispute error")
+	}
+	return nil
+}
+
+func MockResolveDispute(hash string, outcome string, note string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ResolveDispute"), []byte(hash), []byte(outcome), []byte(note)})
+	if res.Status != shim.OK {
+		fmt.Println("ResolveDispute failed", string(res.Message))
+		return errors.New("ResolveDispute error")
+	}
+	return nil
+}
+
+func MockGetDispute(hash string) (*smartcontract.Dispute, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetDispute"), []byte(hash)})
+	if res.Status != shim.OK {
+		fmt.Println("GetDispute failed", string(res.Message))
+		return nil, errors.New("GetDispute error")
+	}
+	var dispute smartcontract.Dispute
+	json.Unmarshal(res.Payload, &dispute)
+	return &dispute, nil
+}
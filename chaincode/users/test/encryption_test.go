@@ -0,0 +1,130 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+var fieldKey = []byte("0123456789abcdef0123456789abcdef")
+
+func NewFieldSigningKey() ([]byte, []byte) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	privateDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		panic(err)
+	}
+	publicDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDer}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer})
+}
+
+func Test_EncryptedUserFields(t *testing.T) {
+	fmt.Println("Test_EncryptedUserFields-----------------")
+	NewStub()
+
+	err := MockCreateUserWithTransient(user1.ID, user1.Name, user1.Email, map[string][]byte{smartcontract.EncryptionKeyTransientKey: fieldKey})
+	if err != nil {
+		t.FailNow()
+	}
+
+	stored, err := MockGetUserPersonalData(user1.ID)
+	assert.Nil(t, err)
+	assert.NotEqual(t, stored.Name, user1.Name)
+	assert.NotEqual(t, stored.Email, user1.Email)
+	assert.Equal(t, stored.EncryptedFields, []string{"email", "name"})
+	assert.NotContains(t, string(Stub.PvtState[smartcontract.PersonalDataCollection][user1.ID]), user1.Name)
+
+	personalData, err := MockGetUserPersonalDataWithTransient(user1.ID, map[string][]byte{smartcontract.DecryptionKeyTransientKey: fieldKey})
+	assert.Nil(t, err)
+	assert.Equal(t, personalData.Name, user1.Name)
+	assert.Equal(t, personalData.Email, user1.Email)
+	assert.Equal(t, len(personalData.EncryptedFields), 0)
+
+	_, err = MockGetUserPersonalDataWithTransient(user1.ID, map[string][]byte{smartcontract.DecryptionKeyTransientKey: []byte("fedcba9876543210fedcba9876543210")})
+	assert.NotNil(t, err)
+
+	// without a key the update is stored in clear
+	assert.Nil(t, MockUpdateUser(user1.ID, user1.Name, user1.Email))
+	stored, err = MockGetUserPersonalData(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, stored.Name, user1.Name)
+}
+
+func Test_SignedEncryptedUserFields(t *testing.T) {
+	fmt.Println("Test_SignedEncryptedUserFields-----------------")
+	NewStub()
+	signingKey, verificationKey := NewFieldSigningKey()
+	_, otherVerificationKey := NewFieldSigningKey()
+
+	err := MockCreateUserWithTransient(user1.ID, user1.Name, user1.Email, map[string][]byte{
+		smartcontract.EncryptionKeyTransientKey: fieldKey,
+		smartcontract.SigningKeyTransientKey:    signingKey,
+	})
+	if err != nil {
+		t.FailNow()
+	}
+	err = MockCreateUserWithTransient(user2.ID, user2.Name, user2.Email, map[string][]byte{smartcontract.EncryptionKeyTransientKey: fieldKey})
+	if err != nil {
+		t.FailNow()
+	}
+
+	personalData, err := MockGetUserPersonalDataWithTransient(user1.ID, map[string][]byte{
+		smartcontract.DecryptionKeyTransientKey:   fieldKey,
+		smartcontract.VerificationKeyTransientKey: verificationKey,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, personalData.Name, user1.Name)
+
+	_, err = MockGetUserPersonalDataWithTransient(user1.ID, map[string][]byte{
+		smartcontract.DecryptionKeyTransientKey:   fieldKey,
+		smartcontract.VerificationKeyTransientKey: otherVerificationKey,
+	})
+	assert.NotNil(t, err)
+	_, err = MockGetUserPersonalDataWithTransient(user2.ID, map[string][]byte{
+		smartcontract.DecryptionKeyTransientKey:   fieldKey,
+		smartcontract.VerificationKeyTransientKey: verificationKey,
+	})
+	assert.NotNil(t, err)
+}
+
+func MockCreateUserWithTransient(id string, name string, email string, transient map[string][]byte) error {
+	res := MockInvokeWithTransient("uuid",
+		[][]byte{
+			[]byte("CreateUser"),
+			[]byte(id),
+		},
+		withPersonalData(name, email, transient))
+	if res.Status != shim.OK {
+		fmt.Println("CreateUser failed", string(res.Message))
+		return errors.New("CreateUser error")
+	}
+	return nil
+}
+
+func MockGetUserPersonalDataWithTransient(id string, transient map[string][]byte) (*smartcontract.PersonalData, error) {
+	res := MockInvokeWithTransient("uuid", [][]byte{[]byte("GetUserPersonalData"), []byte(id)}, transient)
+	if res.Status != shim.OK {
+		fmt.Println("GetUserPersonalData failed", string(res.Message))
+		return nil, errors.New("GetUserPersonalData error")
+	}
+	var personalData smartcontract.PersonalData
+	json.Unmarshal(res.Payload, &personalData)
+	return &personalData, nil
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EraseUserPersonalData(t *testing.T) {
+	fmt.Println("Test_EraseUserPersonalData-----------------")
+	NewStub()
+	MockAddUserMSP("Org3MSP")
+	personalData, _ := json.Marshal(smartcontract.PersonalData{FullName: "Alice Wang", NationalID: "A123456789"})
+	err := MockCreateUserWithTransient(user1.ID, user1.Name, user1.Email, map[string][]byte{smartcontract.PersonalDataTransientKey: personalData})
+	if err != nil {
+		t.FailNow()
+	}
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+	_, err = MockTransferBetweenUsers("tx1", user2.ID, user1.ID, "10", "NTD", "04231910", "for Alice Wang")
+	if err != nil {
+		t.FailNow()
+	}
+	hash := TxHash(user1.ID, transaction1)
+	_, err = MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	if err != nil {
+		t.FailNow()
+	}
+	stored, err := MockGetUserPersonalData(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, stored.NationalID, "A123456789")
+
+	owner := map[string]string{smartcontract.UserIDAttribute: user1.ID}
+	MockSetCreatorWithAttributes("Org3MSP", "owner", owner)
+	assert.Nil(t, MockOpenDispute(hash, "I am Alice Wang and this is not mine"))
+	MockSetCreatorWithAttributes("Org3MSP", "someone", map[string]string{smartcontract.UserIDAttribute: user2.ID})
+	_, err = MockEraseUserPersonalData(user1.ID)
+	assert.NotNil(t, err)
+
+	MockSetCreatorWithAttributes("Org3MSP", "owner", owner)
+	certificate, err := MockEraseUserPersonalData(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, certificate.TxID, "erasure")
+	assert.Equal(t, certificate.Method, smartcontract.ErasureMethodPurge)
+	assert.Equal(t, certificate.ErasedBy, "Org3MSP")
+	assert.Len(t, certificate.ErasedByDigest, 64)
+	_, err = MockEraseUserPersonalData(user1.ID)
+	assert.NotNil(t, err)
+
+	MockSetCreator("Org1MSP", "admin")
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.NotEqual(t, user.ErasedAt, "")
+	assert.Equal(t, len(user.Transactions), 1)
+	_, err = MockGetUserPersonalData(user1.ID)
+	assert.NotNil(t, err)
+	assert.Nil(t, Stub.PvtState[smartcontract.LookupCollection][user1.ID])
+	assert.Nil(t, Stub.PvtState[smartcontract.LookupCollection]["name~john lee~"+user1.ID])
+	dispute, err := MockGetDispute(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, dispute.Reason, "[erased]")
+	assert.Equal(t, dispute.Timeline[0].Note, "[erased]")
+	assert.Equal(t, user.Transfers[0].Reference, "[erased]")
+	payer, err := MockGetUser(user2.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, payer.Transfers[0].Reference, "[erased]")
+	transfer, err := MockGetTransfer("tx1")
+	assert.Nil(t, err)
+	assert.Equal(t, transfer.Reference, "[erased]")
+
+	assert.NotNil(t, MockUpdateUser(user1.ID, user1.Name, user1.Email))
+	assert.NotNil(t, MockCreateUser(user1.ID, user1.Name, user1.Email))
+	stored2, err := MockGetErasureCertificate(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, stored2, certificate)
+}
+
+func Test_DeleteUserPersonalData(t *testing.T) {
+	fmt.Println("Test_DeleteUserPersonalData-----------------")
+	NewStub()
+	personalData, _ := json.Marshal(smartcontract.PersonalData{FullName: "Alice Wang", NationalID: "A123456789"})
+	err := MockCreateUserWithTransient(user1.ID, user1.Name, user1.Email, map[string][]byte{smartcontract.PersonalDataTransientKey: personalData})
+	if err != nil {
+		t.FailNow()
+	}
+	assert.Nil(t, MockDeleteUser(user1.ID))
+
+	// a user created again under the ID does not inherit the personal data
+	assert.Nil(t, MockCreateUser(user1.ID, user1.Name, user1.Email))
+	stored, err := MockGetUserPersonalData(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, stored.NationalID, "")
+}
+
+func MockEraseUserPersonalData(id string) (*smartcontract.ErasureCertificate, error) {
+	res := MockInvokeWithTransient("erasure", [][]byte{[]byte("EraseUserPersonalData"), []byte(id)}, nil)
+	if res.Status != shim.OK {
+		fmt.Println("EraseUserPersonalData failed", string(res.Message))
+		return nil, errors.New("EraseUserPersonalData error")
+	}
+	var certificate smartcontract.ErasureCertificate
+	json.Unmarshal(res.Payload, &certificate)
+	return &certificate, nil
+}
+
+func MockGetErasureCertificate(id string) (*smartcontract.ErasureCertificate, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetErasureCertificate"), []byte(id)})
+	if res.Status != shim.OK {
+		fmt.Println("GetErasureCertificate failed", string(res.Message))
+		return nil, errors.New("GetErasureCertificate error")
+	}
+	var certificate smartcontract.ErasureCertificate
+	json.Unmarshal(res.Payload, &certificate)
+	return &certificate, nil
+}
+
+func MockGetUserPersonalData(id string) (*smartcontract.PersonalData, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetUserPersonalData"), []byte(id)})
+	if res.Status != shim.OK {
+		fmt.Println("GetUserPersonalData failed", string(res.Message))
+		return nil, errors.New("GetUserPersonalData error")
+	}
+	var personalData smartcontract.PersonalData
+	json.Unmarshal(res.Payload, &personalData)
+	return &personalData, nil
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExportUserData(t *testing.T) {
+	fmt.Println("Test_ExportUserData-----------------")
+	NewStub()
+	MockAddUserMSP("Org3MSP")
+	personalData, _ := json.Marshal(smartcontract.PersonalData{FullName: "Alice Wang", NationalID: "A123456789"})
+	err := MockCreateUserWithTransient(user1.ID, user1.Name, user1.Email, map[string][]byte{smartcontract.PersonalDataTransientKey: personalData})
+	if err != nil {
+		t.FailNow()
+	}
+	hash := TxHash(user1.ID, transaction1)
+	_, err = MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	if err != nil {
+		t.FailNow()
+	}
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	assert.Nil(t, MockOpenDispute(hash, "charged twice"))
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	assert.Nil(t, MockGrantConsent(user1.ID, "Org2MSP", tomorrow))
+
+	export, err := MockExportUserData(user1.ID)
+	assert.Nil(t, err)
+	digest := sha256.Sum256([]byte(export.Bundle))
+	assert.Equal(t, export.Digest, hex.EncodeToString(digest[:]))
+
+	var bundle smartcontract.UserDataBundle
+	assert.Nil(t, json.Unmarshal([]byte(export.Bundle), &bundle))
+	assert.Equal(t, bundle.UserId, user1.ID)
+	assert.Equal(t, bundle.Profile.ID, user1.ID)
+	assert.Equal(t, len(bundle.Profile.Transactions), 0)
+	assert.Equal(t, len(bundle.Transactions), 1)
+	assert.Equal(t, bundle.Transactions[0].Hash, hash)
+	assert.Equal(t, len(bundle.Banks), 1)
+	assert.Equal(t, bundle.Banks[0].ID, transaction1.BankId)
+	assert.Equal(t, len(bundle.Disputes), 1)
+	assert.Equal(t, bundle.Disputes[0].Reason, "charged twice")
+	assert.Equal(t, len(bundle.Consents), 1)
+	assert.Equal(t, bundle.Consents[0].MSPID, "Org2MSP")
+	assert.Nil(t, bundle.ErasureCertificate)
+	assert.Nil(t, bundle.PersonalData)
+	assert.True(t, bundle.PersonalDataWithheld)
+
+	MockSetCreator("Org1MSP", "admin")
+	export, err = MockExportUserData(user1.ID)
+	assert.Nil(t, err)
+	bundle = smartcontract.UserDataBundle{}
+	assert.Nil(t, json.Unmarshal([]byte(export.Bundle), &bundle))
+	assert.Equal(t, bundle.PersonalData.NationalID, "A123456789")
+	assert.Equal(t, bundle.PersonalData.Email, user1.Email)
+	assert.False(t, bundle.PersonalDataWithheld)
+
+	MockSetCreator("Org4MSP", "bank")
+	_, err = MockExportUserData(user1.ID)
+	assert.NotNil(t, err)
+}
+
+func MockExportUserData(id string) (*smartcontract.UserDataExport, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ExportUserData"), []byte(id)})
+	if res.Status != shim.OK {
+		fmt.Println("ExportUserData failed", string(res.Message))
+		return nil, errors.New("ExportUserData error")
+	}
+	var export smartcontract.UserDataExport
+	json.Unmarshal(res.Payload, &export)
+	return &export, nil
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CreateUserIdempotent(t *testing.T) {
+	fmt.Println("Test_CreateUserIdempotent-----------------")
+	NewStub()
+
+	assert.Nil(t, MockCreateUserWithRequestID(user1.ID, user1.Name, user1.Email, "req-1"))
+	assert.Nil(t, MockCreateUserWithRequestID(user1.ID, user1.Name, user1.Email, "req-1"))
+	assert.NotNil(t, MockCreateUserWithRequestID(user1.ID, user1.Name, user1.Email, "req-2"))
+	assert.NotNil(t, MockCreateUser(user1.ID, user1.Name, user1.Email))
+
+	// a reused request ID with other arguments is no retry
+	assert.NotNil(t, MockCreateUserWithRequestID(user2.ID, user2.Name, user2.Email, "req-1"))
+	// request IDs of other callers are their own
+	MockSetCreator("Org2MSP", "gateway")
+	assert.Nil(t, MockCreateUserWithRequestID(user2.ID, user2.Name, user2.Email, "req-1"))
+	exists, err := MockUserExists(user2.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, exists, true)
+}
+
+func Test_CreateTransactionIdempotent(t *testing.T) {
+	fmt.Println("Test_CreateTransactionIdempotent-----------------")
+	NewStub()
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+
+	// each retry is signed again, so the arguments differ while the request ID does not
+	for i := 0; i < 2; i++ {
+		hash, err := MockCreateTransactionWithRequestID(user1.ID, transaction1, "req-1")
+		assert.Nil(t, err)
+		assert.Equal(t, hash, TxHash(user1.ID, transaction1))
+	}
+	_, err = MockCreateTransactionWithRequestID(user1.ID, transaction2, "req-1")
+	assert.NotNil(t, err)
+
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 1)
+
+	bank, err := MockGetBankByID(transaction1.BankId)
+	assert.Nil(t, err)
+	assert.Equal(t, bank.TransactionCount, 1)
+}
+
+func MockCreateUserWithRequestID(id string, name string, email string, requestId string) error {
+	res := MockInvokeWithTransient("uuid",
+		[][]byte{
+			[]byte("CreateUser"),
+			[]byte(id),
+		},
+		withPersonalData(name, email, map[string][]byte{smartcontract.IdempotencyTransientKey: []byte(requestId)}))
+	if res.Status != shim.OK {
+		fmt.Println("CreateUser failed", string(res.Message))
+		return errors.New("CreateUser error")
+	}
+	return nil
+}
+
+func MockCreateTransactionWithRequestID(userId string, transaction smartcontract.Transaction, requestId string) (string, error) {
+	res := MockInvokeWithTransient("uuid",
+		[][]byte{
+			[]byte("CreateTransaction"),
+			[]byte(userId),
+			[]byte(TxHash(userId, transaction)),
+			[]byte(transaction.Amount),
+			[]byte(transaction.Currency),
+			[]byte(transaction.Date),
+			[]byte(transaction.BankId),
+			[]byte(transaction.Reference),
+			[]byte(BankSignature(userId, transaction)),
+		},
+		map[string][]byte{smartcontract.IdempotencyTransientKey: []byte(requestId)})
+	if res.Status != shim.OK {
+		fmt.Println("CreateTransaction failed", string(res.Message))
+		return "", errors.New("CreateTransaction error")
+	}
+	return string(res.Payload), nil
+}
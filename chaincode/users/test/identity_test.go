@@ -0,0 +1,59 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// attributesOID certificate extension in which the Fabric CA stores identity attributes
+var attributesOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// MockSetCreator makes the following invocations on Stub come from a new client
+// certificate with commonName issued for mspID
+func MockSetCreator(mspID string, commonName string) {
+	MockSetCreatorWithAttributes(mspID, commonName, nil)
+}
+
+// MockSetCreatorWithAttributes is MockSetCreator with Fabric CA attributes such as user_id
+// or bank_id added to the certificate
+func MockSetCreatorWithAttributes(mspID string, commonName string, attributes map[string]string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{mspID}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if attributes != nil {
+		attributesJson, err := json.Marshal(map[string]interface{}{"attrs": attributes})
+		if err != nil {
+			panic(err)
+		}
+		template.ExtraExtensions = []pkix.Extension{{Id: attributesOID, Value: attributesJson}}
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer})
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{Mspid: mspID, IdBytes: certPem})
+	if err != nil {
+		panic(err)
+	}
+	Stub.Creator = creator
+}
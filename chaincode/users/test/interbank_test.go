@@ -0,0 +1,134 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SettlementBatchWorkflow(t *testing.T) {
+	fmt.Println("Test_SettlementBatchWorkflow-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+
+	cathay, fubon := "04231910", "03750168"
+	_, err := MockInterbankTransfer("tx1", user1.ID, user2.ID, "100", "NTD", cathay, fubon)
+	assert.Nil(t, err)
+	_, err = MockInterbankTransfer("tx2", user2.ID, user1.ID, "30", "NTD", fubon, cathay)
+	assert.Nil(t, err)
+	_, err = MockInterbankTransfer("tx3", user2.ID, user1.ID, "5", "USD", fubon, cathay)
+	assert.Nil(t, err)
+	_, err = MockTransferBetweenUsers("tx4", user1.ID, user2.ID, "1", "NTD", cathay, "")
+	assert.Nil(t, err)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	batches, err := MockProposeSettlementBatches(today, today)
+	assert.Nil(t, err)
+	assert.Equal(t, len(batches), 1)
+	batch := batches[0]
+	assert.Equal(t, batch.BankA, fubon)
+	assert.Equal(t, batch.Status, smartcontract.SettlementProposed)
+	assert.Equal(t, batch.TransferIDs, []string{"tx1", "tx2", "tx3"})
+	assert.Equal(t, batch.Positions, []*smartcontract.NetPosition{
+		{Currency: "NTD", Debtor: cathay, Creditor: fubon, Amount: "70"},
+		{Currency: "USD", Debtor: fubon, Creditor: cathay, Amount: "5"},
+	})
+
+	// transfers already in a batch are not netted again
+	batches, err = MockProposeSettlementBatches(today, today)
+	assert.Nil(t, err)
+	assert.Equal(t, len(batches), 0)
+	transfer, err := MockGetTransfer("tx1")
+	assert.Nil(t, err)
+	assert.Equal(t, transfer.SettlementBatchID, batch.ID)
+
+	payload, err := smartcontract.SettlementBatchPayload(batch)
+	if err != nil {
+		t.FailNow()
+	}
+	otherKey, _ := NewBankKey()
+	assert.NotNil(t, MockConfirmSettlementBatch(cathay, batch.ID, Sign(otherKey, payload)))
+	assert.Nil(t, MockConfirmSettlementBatch(cathay, batch.ID, Sign(bankKeys[cathay], payload)))
+	assert.NotNil(t, MockConfirmSettlementBatch(cathay, batch.ID, Sign(bankKeys[cathay], payload)))
+	assert.NotNil(t, MockSettleSettlementBatch(batch.ID))
+
+	assert.Nil(t, MockConfirmSettlementBatch(fubon, batch.ID, Sign(bankKeys[fubon], payload)))
+	batch, err = MockGetSettlementBatch(batch.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, batch.Status, smartcontract.SettlementConfirmed)
+
+	MockSetCreator("Org2MSP", "teller")
+	assert.NotNil(t, MockSettleSettlementBatch(batch.ID))
+	MockSetCreator("Org1MSP", "admin")
+	assert.Nil(t, MockSettleSettlementBatch(batch.ID))
+	batch, err = MockGetSettlementBatch(batch.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, batch.Status, smartcontract.SettlementSettled)
+}
+
+func MockInterbankTransfer(txId string, fromUserId string, toUserId string, amount string, currency string, fromBankId string, toBankId string) (string, error) {
+	res := Stub.MockInvoke(txId,
+		[][]byte{
+			[]byte("InterbankTransfer"),
+			[]byte(fromUserId),
+			[]byte(toUserId),
+			[]byte(amount),
+			[]byte(currency),
+			[]byte(fromBankId),
+			[]byte(toBankId),
+			[]byte(""),
+		})
+	if res.Status != shim.OK {
+		fmt.Println("InterbankTransfer failed", string(res.Message))
+		return "", errors.New("InterbankTransfer error")
+	}
+	return string(res.Payload), nil
+}
+
+func MockProposeSettlementBatches(windowStart string, windowEnd string) ([]*smartcontract.SettlementBatch, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ProposeSettlementBatches"), []byte(windowStart), []byte(windowEnd)})
+	if res.Status != shim.OK {
+		fmt.Println("ProposeSettlementBatches failed", string(res.Message))
+		return nil, errors.New("ProposeSettlementBatches error")
+	}
+	var batches []*smartcontract.SettlementBatch
+	json.Unmarshal(res.Payload, &batches)
+	return batches, nil
+}
+
+func MockGetSettlementBatch(batchId string) (*smartcontract.SettlementBatch, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetSettlementBatch"), []byte(batchId)})
+	if res.Status != shim.OK {
+		fmt.Println("GetSettlementBatch failed", string(res.Message))
+		return nil, errors.New("GetSettlementBatch error")
+	}
+	var batch smartcontract.SettlementBatch
+	json.Unmarshal(res.Payload, &batch)
+	return &batch, nil
+}
+
+func MockConfirmSettlementBatch(bankId string, batchId string, signature string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ConfirmSettlementBatch"), []byte(bankId), []byte(batchId), []byte(signature)})
+	if res.Status != shim.OK {
+		fmt.Println("ConfirmSettlementBatch failed", string(res.Message))
+		return errors.New("ConfirmSettlementBatch error")
+	}
+	return nil
+}
+
+func MockSettleSettlementBatch(batchId string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("SettleSettlementBatch"), []byte(batchId)})
+	if res.Status != shim.OK {
+		fmt.Println("SettleSettlementBatch failed", string(res.Message))
+		return errors.New("SettleSettlementBatch error")
+	}
+	return nil
+}
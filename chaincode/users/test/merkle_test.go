@@ -0,0 +1,58 @@
+package test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"users/merkle"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MerkleProofs(t *testing.T) {
+	fmt.Println("Test_MerkleProofs-----------------")
+	a, b, c := merkle.LeafHash([]byte("a")), merkle.LeafHash([]byte("b")), merkle.LeafHash([]byte("c"))
+	assert.Equal(t, merkle.Root([][]byte{a, b, c}), merkle.NodeHash(merkle.NodeHash(a, b), c))
+
+	var leaves [][]byte
+	var frontier merkle.Frontier
+	nodes := map[[2]int][]byte{}
+	node := func(level int, index int) ([]byte, error) {
+		hash, ok := nodes[[2]int{level, index}]
+		if !ok {
+			return nil, fmt.Errorf("no node %d/%d", level, index)
+		}
+		return hash, nil
+	}
+	for n := 1; n <= 17; n++ {
+		leaves = append(leaves, merkle.LeafHash([]byte(strconv.Itoa(n))))
+		root := hex.EncodeToString(merkle.Root(leaves))
+		completed, err := frontier.AppendNodes(leaves[n-1])
+		assert.Nil(t, err)
+		for _, completedNode := range completed {
+			nodes[[2]int{completedNode.Level, completedNode.Index}] = completedNode.Hash
+		}
+		frontierRoot, err := frontier.Root()
+		assert.Nil(t, err)
+		assert.Equal(t, hex.EncodeToString(frontierRoot), root)
+		for i := range leaves {
+			proof, err := merkle.Prove(leaves, i)
+			assert.Nil(t, err)
+			assert.True(t, merkle.Verify(leaves[i], proof, root), "leaf %d of %d", i, n)
+			fromNodes, err := merkle.ProveFromNodes(n, i, node)
+			assert.Nil(t, err)
+			assert.Equal(t, fromNodes, proof, "leaf %d of %d", i, n)
+			if n > 1 {
+				assert.False(t, merkle.Verify(leaves[(i+1)%n], proof, root))
+			}
+		}
+	}
+
+	proof, _ := merkle.Prove(leaves, 3)
+	proof.Siblings = proof.Siblings[1:]
+	assert.False(t, merkle.Verify(leaves[3], proof, hex.EncodeToString(merkle.Root(leaves))))
+	_, err := merkle.Prove(leaves, len(leaves))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+	"users/verifier"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetTransactionProof(t *testing.T) {
+	fmt.Println("Test_GetTransactionProof-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	var hashes []string
+	for _, date := range []string{"2022-03-01", "2022-03-02", "2022-03-03", "2022-03-04", "2022-03-05"} {
+		transaction := smartcontract.Transaction{Amount: "10", Currency: "USD", Date: date, BankId: "04231910"}
+		hash := TxHash(user1.ID, transaction)
+		_, err := MockCreateTransaction(user1.ID, hash, transaction.Amount, transaction.Currency, transaction.Date, transaction.BankId)
+		if err != nil {
+			t.FailNow()
+		}
+		hashes = append(hashes, hash)
+	}
+
+	tree, err := MockGetUserMerkleTree(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, tree.Frontier.Size, 5)
+	for i, hash := range hashes {
+		proof, err := MockGetTransactionProof(user1.ID, hash)
+		assert.Nil(t, err)
+		assert.Equal(t, proof.Proof.Index, i)
+		assert.Nil(t, verifier.VerifyRoot(proof, tree.Root))
+	}
+
+	proof, err := MockGetTransactionProof(user1.ID, hashes[2])
+	assert.Nil(t, err)
+	proof.Hash = hashes[3]
+	assert.NotNil(t, verifier.Verify(proof))
+	_, err = MockGetTransactionProof(user2.ID, hashes[2])
+	assert.NotNil(t, err)
+
+	signingKey, publicKey := NewFieldSigningKey()
+	assert.Nil(t, MockRegisterArchiveKey(string(publicKey)))
+	_, err = MockArchiveTransactions(user1.ID, "2022-03-03", signingKey)
+	assert.Nil(t, err)
+	proof, err = MockGetTransactionProof(user1.ID, hashes[0])
+	assert.Nil(t, err)
+	assert.Nil(t, verifier.VerifyRoot(proof, tree.Root))
+
+	MockSetCreator("Org2MSP", "bank")
+	_, err = MockGetTransactionProof(user1.ID, hashes[0])
+	assert.NotNil(t, err)
+}
+
+func Test_DeleteUserRecords(t *testing.T) {
+	fmt.Println("Test_DeleteUserRecords-----------------")
+	NewStub()
+	MockAddUserMSP("Org3MSP")
+	NewTokenStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	hash := TxHash(user1.ID, transaction1)
+	_, err := MockCreateTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	if err != nil {
+		t.FailNow()
+	}
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	account, err := MockLinkTokenAccount(user1.ID)
+	if err != nil {
+		t.FailNow()
+	}
+	MockSetCreator("Org1MSP", "admin")
+	orderId, err := MockCreateStandingOrder("order1", user1.ID, "100", "USD", "04231910", smartcontract.ScheduleDaily)
+	if err != nil {
+		t.FailNow()
+	}
+
+	assert.Nil(t, MockDeleteUser(user1.ID))
+	order, err := MockGetStandingOrder(orderId)
+	assert.Nil(t, err)
+	assert.Equal(t, order.Status, smartcontract.StandingOrderCancelled)
+	_, err = MockGetUserByTokenAccount(account.AccountID)
+	assert.NotNil(t, err)
+
+	// a user created again under the ID starts a tree of its own
+	assert.Nil(t, MockCreateUser(user1.ID, user1.Name, user1.Email))
+	_, err = MockGetUserMerkleTree(user1.ID)
+	assert.NotNil(t, err)
+	_, err = MockGetTransactionProof(user1.ID, hash)
+	assert.NotNil(t, err)
+	transaction := smartcontract.Transaction{Amount: "10", Currency: "USD", Date: "2022-03-01", BankId: "04231910"}
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction), transaction.Amount, transaction.Currency, transaction.Date, transaction.BankId)
+	assert.Nil(t, err)
+	tree, err := MockGetUserMerkleTree(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, tree.Frontier.Size, 1)
+}
+
+func MockGetUserMerkleTree(userId string) (*smartcontract.UserMerkleTree, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetUserMerkleTree"), []byte(userId)})
+	if res.Status != shim.OK {
+		fmt.Println("GetUserMerkleTree failed", string(res.Message))
+		return nil, errors.New("GetUserMerkleTree error")
+	}
+	var tree smartcontract.UserMerkleTree
+	json.Unmarshal(res.Payload, &tree)
+	return &tree, nil
+}
+
+func MockGetTransactionProof(userId string, hash string) (*verifier.TransactionProof, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetTransactionProof"), []byte(userId), []byte(hash)})
+	if res.Status != shim.OK {
+		fmt.Println("GetTransactionProof failed", string(res.Message))
+		return nil, errors.New("GetTransactionProof error")
+	}
+	var proof verifier.TransactionProof
+	json.Unmarshal(res.Payload, &proof)
+	return &proof, nil
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SearchUsersByNamePrefix(t *testing.T) {
+	fmt.Println("Test_SearchUsersByNamePrefix-----------------")
+	NewStub()
+	MockCreateUser("3", "Wang Xiaoming", "wang.xm@g.com")
+	MockCreateUser("4", "王小明", "xiaoming@g.com")
+	MockCreateUser("5", "Wang Li", "wang.li@g.com")
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUserWithTransient(user2.ID, "Wang Amy", user2.Email, map[string][]byte{smartcontract.EncryptionKeyTransientKey: fieldKey})
+
+	// the index is private, encrypted names included
+	for key := range Stub.State {
+		assert.False(t, strings.HasPrefix(key, "name~"))
+	}
+	page, err := MockSearchUsersByNamePrefix("ＷＡＮＧ", 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Users), 3)
+	assert.Equal(t, page.Users[0].ID, user2.ID)
+	assert.Equal(t, page.Users[1].ID, "5")
+	assert.Equal(t, page.Users[2].ID, "3")
+
+	page, err = MockSearchUsersByNamePrefix("wang", 2, "")
+	assert.Nil(t, err)
+	assert.Equal(t, page.Users[1].ID, "5")
+	assert.Equal(t, page.Bookmark, "5")
+	page, err = MockSearchUsersByNamePrefix("wang", 2, page.Bookmark)
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Users), 1)
+	assert.Equal(t, page.Users[0].ID, "3")
+	_, err = MockSearchUsersByNamePrefix("wang", 1, user1.ID)
+	assert.NotNil(t, err)
+
+	page, err = MockSearchUsersByNamePrefix("王小", 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Users), 1)
+	assert.Equal(t, page.Users[0].ID, "4")
+
+	assert.Nil(t, MockUpdateUser("5", "Chen Li", "wang.li@g.com"))
+	assert.Nil(t, MockDeleteUser("3"))
+	page, err = MockSearchUsersByNamePrefix("wang", 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Users), 1)
+	page, err = MockSearchUsersByNamePrefix("chen", 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Users), 1)
+
+	_, err = MockEraseUserPersonalData("5")
+	assert.Nil(t, err)
+	page, err = MockSearchUsersByNamePrefix("chen", 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Users), 0)
+
+	MockSetCreator("Org2MSP", "bank")
+	page, err = MockSearchUsersByNamePrefix("john", 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Users), 0)
+}
+
+func MockSearchUsersByNamePrefix(prefix string, pageSize int, bookmark string) (*smartcontract.UserPage, error) {
+	res := MockInvokeWithTransient("uuid", [][]byte{[]byte("SearchUsersByNamePrefix"), []byte(prefix), []byte(strconv.Itoa(pageSize)), []byte(bookmark)}, nil)
+	if res.Status != shim.OK {
+		fmt.Println("SearchUsersByNamePrefix failed", string(res.Message))
+		return nil, errors.New("SearchUsersByNamePrefix error")
+	}
+	var page smartcontract.UserPage
+	json.Unmarshal(res.Payload, &page)
+	return &page, nil
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewWatchlistStub() {
+	NewStub()
+	MockAddWatchlistEntry("cn-1", "王小明", "", smartcontract.WatchlistBlock)
+	MockAddWatchlistEntry("un-2", "Wang Xiaoming", "A-123 456", smartcontract.WatchlistFlag)
+	MockAddWatchlistEntry("ofac-3", "Mohammed Al Hassan", "", smartcontract.WatchlistBlock)
+}
+
+func Test_ScreenName(t *testing.T) {
+	fmt.Println("Test_ScreenName-----------------")
+	NewWatchlistStub()
+
+	cases := []struct {
+		name    string
+		entries []string
+	}{
+		{"王 小明", []string{"cn-1"}},
+		{"ＷＡＮＧ Xiǎo-Míng", []string{"un-2"}},
+		{"Mohamed Al-Hasan", []string{"ofac-3"}},
+		{"Alice Chen", []string{}},
+		{"王大明", []string{}},
+	}
+	for _, c := range cases {
+		matches, err := MockScreenName(c.name)
+		assert.Nil(t, err)
+		entries := []string{}
+		for _, match := range matches {
+			entries = append(entries, match.EntryID)
+		}
+		assert.Equal(t, entries, c.entries, c.name)
+	}
+
+	MockSetCreator("Org2MSP", "teller")
+	assert.NotNil(t, MockAddWatchlistEntry("x-4", "Someone", "", smartcontract.WatchlistFlag))
+}
+
+func Test_ScreeningOnUsersAndTransactions(t *testing.T) {
+	fmt.Println("Test_ScreeningOnUsersAndTransactions-----------------")
+	NewWatchlistStub()
+
+	assert.NotNil(t, MockCreateUser("11", "王小明", "wang@gmail.com"))
+	assert.Nil(t, MockCreateUser("12", "Wang Xiao Ming", "wang@gmail.com"))
+	assert.Nil(t, MockCreateUser(user1.ID, user1.Name, user1.Email))
+	assert.NotNil(t, MockUpdateUser(user1.ID, "Mohammed Al Hassan", user1.Email))
+
+	alerts, err := MockListAlerts(smartcontract.AlertStatusOpen)
+	assert.Nil(t, err)
+	assert.Equal(t, len(alerts), 1)
+	assert.Equal(t, alerts[0].UserId, "12")
+	assert.Equal(t, alerts[0].Rule, smartcontract.AlertRuleSanctions)
+
+	hash := TxHash("12", transaction1)
+	_, err = MockCreateTransaction("12", hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.Nil(t, err)
+	alerts, err = MockListAlerts(smartcontract.AlertStatusOpen)
+	assert.Nil(t, err)
+	assert.Equal(t, len(alerts), 2)
+	assert.Equal(t, alerts[1].TransactionHash, hash)
+	assert.Equal(t, alerts[1].Amount, transaction1.Amount)
+
+	assert.Nil(t, MockRemoveWatchlistEntry("cn-1"))
+	assert.Nil(t, MockCreateUser("11", "王小明", "wang@gmail.com"))
+}
+
+func Test_ScreeningEncryptedUsers(t *testing.T) {
+	fmt.Println("Test_ScreeningEncryptedUsers-----------------")
+	NewStub()
+	encrypted := map[string][]byte{smartcontract.EncryptionKeyTransientKey: fieldKey}
+	assert.Nil(t, MockCreateUserWithTransient("12", "Wang Xiao Ming", "wang@gmail.com", encrypted))
+	assert.Nil(t, MockCreateUserWithTransient("13", "Alice Chen", "alice@gmail.com", encrypted))
+	assert.Nil(t, MockCreateUserWithTransient(user1.ID, user1.Name, user1.Email, encrypted))
+	stored, err := MockGetUserPersonalData("12")
+	assert.Nil(t, err)
+	assert.NotEqual(t, stored.Name, "Wang Xiao Ming")
+	// the user is screened by data kept off the public ledger
+	assert.NotContains(t, string(Stub.State["12"]), "wang")
+	assert.Contains(t, string(Stub.PvtState[smartcontract.LookupCollection]["12"]), "wang")
+
+	MockAddWatchlistEntry("un-2", "Wang Xiaoming", "", smartcontract.WatchlistFlag)
+	MockAddWatchlistEntry("ofac-3", "Alice Chen", "", smartcontract.WatchlistBlock)
+
+	hash := TxHash("12", transaction1)
+	_, err = MockCreateTransaction("12", hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.Nil(t, err)
+	_, err = MockCreateTransaction("13", TxHash("13", transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.NotNil(t, err)
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.Nil(t, err)
+
+	alerts, err := MockListAlerts(smartcontract.AlertStatusOpen)
+	assert.Nil(t, err)
+	assert.Equal(t, len(alerts), 1)
+	assert.Equal(t, alerts[0].UserId, "12")
+	assert.Equal(t, alerts[0].TransactionHash, hash)
+	assert.NotContains(t, alerts[0].Detail, "Wang Xiao Ming")
+}
+
+func MockAddWatchlistEntry(id string, name string, identifiers string, action string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("AddWatchlistEntry"), []byte(id), []byte(name), []byte(identifiers), []byte(action)})
+	if res.Status != shim.OK {
+		fmt.Println("AddWatchlistEntry failed", string(res.Message))
+		return errors.New("AddWatchlistEntry error")
+	}
+	return nil
+}
+
+func MockRemoveWatchlistEntry(id string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("RemoveWatchlistEntry"), []byte(id)})
+	if res.Status != shim.OK {
+		fmt.Println("RemoveWatchlistEntry failed", string(res.Message))
+		return errors.New("RemoveWatchlistEntry error")
+	}
+	return nil
+}
+
+func MockScreenName(name string) ([]*smartcontract.SanctionsMatch, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ScreenName"), []byte(name)})
+	if res.Status != shim.OK {
+		fmt.Println("ScreenName failed", string(res.Message))
+		return nil, errors.New("ScreenName error")
+	}
+	var matches []*smartcontract.SanctionsMatch
+	json.Unmarshal(res.Payload, &matches)
+	return matches, nil
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenContract stands in for token-erc-20, keeping balances the same way without
+// checking allowances
+type tokenContract struct {
+	contractapi.Contract
+}
+
+func (c *tokenContract) SetBalance(ctx contractapi.TransactionContextInterface, account string, value int) error {
+	return ctx.GetStub().PutState(account, []byte(strconv.Itoa(value)))
+}
+
+func (c *tokenContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string) (int, error) {
+	balanceBytes, _ := ctx.GetStub().GetState(account)
+	if balanceBytes == nil {
+		return 0, fmt.Errorf("the account %s does not exist", account)
+	}
+	return strconv.Atoi(string(balanceBytes))
+}
+
+func (c *tokenContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, value int) error {
+	fromBytes, _ := ctx.GetStub().GetState(from)
+	toBytes, _ := ctx.GetStub().GetState(to)
+	fromBalance, _ := strconv.Atoi(string(fromBytes))
+	toBalance, _ := strconv.Atoi(string(toBytes))
+	if fromBalance < value {
+		return fmt.Errorf("client account %s has insufficient funds", from)
+	}
+	ctx.GetStub().PutState(from, []byte(strconv.Itoa(fromBalance-value)))
+	return ctx.GetStub().PutState(to, []byte(strconv.Itoa(toBalance+value)))
+}
+
+func NewTokenStub() *shimtest.MockStub {
+	tokenChaincode, err := contractapi.NewChaincode(new(tokenContract))
+	if err != nil {
+		panic(err)
+	}
+	tokenStub := shimtest.NewMockStub(smartcontract.TokenChaincodeName, tokenChaincode)
+	Stub.MockPeerChaincode(smartcontract.TokenChaincodeName, tokenStub, "")
+	return tokenStub
+}
+
+func Test_CreateTransactionWithSettlement(t *testing.T) {
+	fmt.Println("Test_CreateTransactionWithSettlement-----------------")
+	NewStub()
+	tokenStub := NewTokenStub()
+	tokenStub.MockInvoke("uuid", [][]byte{[]byte("SetBalance"), []byte("alice"), []byte("300")})
+
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+
+	settlement := smartcontract.SettlementRequest{From: "alice", To: "bob"}
+	_, err = MockCreateSettledTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId, settlement)
+	assert.Nil(t, err)
+
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 1)
+	assert.Equal(t, user.Transactions[0].TokenTransfer.Value, 200)
+	assert.Equal(t, user.Transactions[0].TokenTransfer.To, "bob")
+	assert.Equal(t, string(tokenStub.State["alice"]), "100")
+	assert.Equal(t, string(tokenStub.State["bob"]), "200")
+	assert.Equal(t, user.Transactions[0].TokenTransfer.Chaincode, smartcontract.TokenChaincodeName)
+
+	free := smartcontract.Transaction{Amount: "0", Currency: "USD", Date: "2022-04-15", BankId: transaction1.BankId}
+	_, err = MockCreateSettledTransaction(user1.ID, TxHash(user1.ID, free), free.Amount, free.Currency, free.Date, free.BankId, settlement)
+	assert.NotNil(t, err)
+
+	// the second transfer is rejected, so the transaction is not recorded either
+	_, err = MockCreateSettledTransaction(user1.ID, TxHash(user1.ID, transaction2), transaction2.Amount, transaction2.Currency, transaction2.Date, transaction2.BankId, settlement)
+	assert.NotNil(t, err)
+
+	user, err = MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 1)
+	_, err = MockGetUserByTransactionHash(TxHash(user1.ID, transaction2))
+	assert.NotNil(t, err)
+}
+
+func MockCreateSettledTransaction(userId string, hash string, amount string, currency string, date string, bankId string, settlement smartcontract.SettlementRequest) (string, error) {
+	settlementJson, _ := json.Marshal(settlement)
+	transaction := smartcontract.Transaction{Amount: amount, Currency: currency, Date: date, BankId: bankId}
+	res := MockInvokeWithTransient("uuid",
+		[][]byte{
+			[]byte("CreateTransaction"),
+			[]byte(userId),
+			[]byte(hash),
+			[]byte(amount),
+			[]byte(currency),
+			[]byte(date),
+			[]byte(bankId),
+			[]byte(""),
+			[]byte(BankSignature(userId, transaction)),
+		},
+		map[string][]byte{smartcontract.SettlementTransientKey: settlementJson})
+	if res.Status != shim.OK {
+		fmt.Println("CreateTransaction failed", string(res.Message))
+		return "", errors.New("CreateTransaction error")
+	}
+	return string(res.Payload), nil
+}
@@ -0,0 +1,151 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+// bankKeys signing keys of the banks created by InitLedger, registered by NewStub
+var bankKeys = map[string]*ecdsa.PrivateKey{}
+
+func NewBankKey() (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer}))
+}
+
+func Sign(key *ecdsa.PrivateKey, payload []byte) string {
+	digest := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+// BankSignature signs transaction recorded for userId with the key of its bank, or returns
+// an empty signature when the bank has no key
+func BankSignature(userId string, transaction smartcontract.Transaction) string {
+	key, ok := bankKeys[transaction.BankId]
+	if !ok {
+		return ""
+	}
+	payload, err := smartcontract.CanonicalTransaction(userId, transaction)
+	if err != nil {
+		return ""
+	}
+	return Sign(key, payload)
+}
+
+func MockRegisterBankKeys() {
+	for _, bankId := range []string{"04231910", "03750168"} {
+		key, publicKey := NewBankKey()
+		if MockRegisterBankKey(bankId, publicKey) == nil {
+			bankKeys[bankId] = key
+		}
+	}
+}
+
+func Test_CreateTransactionRequiresBankSignature(t *testing.T) {
+	fmt.Println("Test_CreateTransactionRequiresBankSignature-----------------")
+	NewStub()
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+	hash := TxHash(user1.ID, transaction1)
+
+	_, err = MockCreateSignedTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId, "")
+	assert.NotNil(t, err)
+
+	otherBank := transaction1
+	otherBank.BankId = "03750168"
+	_, err = MockCreateSignedTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId, BankSignature(user1.ID, otherBank))
+	assert.NotNil(t, err)
+
+	_, err = MockCreateSignedTransaction(user1.ID, hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId, BankSignature(user1.ID, transaction1))
+	assert.Nil(t, err)
+
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 1)
+	assert.NotEqual(t, user.Transactions[0].Signature, "")
+}
+
+func Test_RotateBankKey(t *testing.T) {
+	fmt.Println("Test_RotateBankKey-----------------")
+	NewStub()
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+	bankId := transaction1.BankId
+	oldKey := bankKeys[bankId]
+	newKey, newPublicKey := NewBankKey()
+
+	payload, err := smartcontract.KeyRotationPayload(bankId, 2, newPublicKey)
+	if err != nil {
+		t.FailNow()
+	}
+	// signed by the new key only, not by the one on record
+	assert.NotNil(t, MockRotateBankKey(bankId, newPublicKey, Sign(newKey, payload)))
+	// the bare key, or a payload for another version or bank, is not a rotation request
+	assert.NotNil(t, MockRotateBankKey(bankId, newPublicKey, Sign(oldKey, []byte(newPublicKey))))
+	otherVersion, _ := smartcontract.KeyRotationPayload(bankId, 3, newPublicKey)
+	assert.NotNil(t, MockRotateBankKey(bankId, newPublicKey, Sign(oldKey, otherVersion)))
+	otherBank, _ := smartcontract.KeyRotationPayload("03750168", 2, newPublicKey)
+	assert.NotNil(t, MockRotateBankKey(bankId, newPublicKey, Sign(oldKey, otherBank)))
+	assert.Nil(t, MockRotateBankKey(bankId, newPublicKey, Sign(oldKey, payload)))
+	// the signed rotation cannot be replayed
+	assert.NotNil(t, MockRotateBankKey(bankId, newPublicKey, Sign(oldKey, payload)))
+
+	bank, err := MockGetBankByID(bankId)
+	assert.Nil(t, err)
+	assert.Equal(t, bank.KeyVersion, 2)
+	assert.Equal(t, bank.PublicKey, newPublicKey)
+
+	// transactions signed with the retired key are refused
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, bankId)
+	assert.NotNil(t, err)
+
+	bankKeys[bankId] = newKey
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, bankId)
+	assert.Nil(t, err)
+}
+
+func MockRegisterBankKey(bankId string, publicKey string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("RegisterBankKey"), []byte(bankId), []byte(publicKey)})
+	if res.Status != shim.OK {
+		fmt.Println("RegisterBankKey failed", string(res.Message))
+		return errors.New("RegisterBankKey error")
+	}
+	return nil
+}
+
+func MockRotateBankKey(bankId string, newPublicKey string, signature string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("RotateBankKey"), []byte(bankId), []byte(newPublicKey), []byte(signature)})
+	if res.Status != shim.OK {
+		fmt.Println("RotateBankKey failed", string(res.Message))
+		return errors.New("RotateBankKey error")
+	}
+	return nil
+}
@@ -18,19 +18,25 @@ import (
 
 var Stub *shimtest.MockStub
 var Scc *contractapi.ContractChaincode
-var user1 smartcontract.User = smartcontract.User{
+// testUser a user ID with the name and email of its PersonalData
+type testUser struct {
+	ID    string
+	Name  string
+	Email string
+}
+
+var user1 testUser = testUser{
 	ID:    "1",
 	Name:  "John Lee",
 	Email: "john.lee@g.com",
 }
-var user2 smartcontract.User = smartcontract.User{
+var user2 testUser = testUser{
 	ID:    "2",
 	Name:  "Amy Lin",
 	Email: "amy.lin@g.com",
 }
 
 var transaction1 smartcontract.Transaction = smartcontract.Transaction{
-	Hash:      "0x000000001",
 	Amount:    "200",
 	Currency:  "USD",
 	Date:      "2022-04-14",
@@ -38,7 +44,6 @@ var transaction1 smartcontract.Transaction = smartcontract.Transaction{
 }
 
 var transaction2 smartcontract.Transaction = smartcontract.Transaction{
-	Hash:      "0x000000002",
 	Amount:    "500",
 	Currency:  "NTD",
 	Date:      "2022-04-16",
@@ -56,13 +61,16 @@ func setup() {
 }
 
 func NewStub() {
-	Scc, err := contractapi.NewChaincode(new(smartcontract.SmartContract))
+	var err error
+	Scc, err = contractapi.NewChaincode(smartcontract.NewSmartContract())
 	if err != nil {
 		log.Println("NewChaincode failed", err)
 		os.Exit(0)
 	}
 	Stub = shimtest.NewMockStub("main", Scc)
 	MockInitLedger()
+	MockSetCreator("Org1MSP", "admin")
+	MockRegisterBankKeys()
 }
 
 func Test_CreateUser(t *testing.T) {
@@ -106,8 +114,18 @@ func Test_GetUser(t *testing.T) {
 	}
 
 	assert.Equal(t, userJson.ID, user1.ID)
-	assert.Equal(t, userJson.Name, user1.Name)
-	assert.Equal(t, userJson.Email, user1.Email)
+	assert.NotEqual(t, userJson.CreatedAt, "")
+	assert.Equal(t, userJson.UpdatedAt, userJson.CreatedAt)
+
+	personalData, err := MockGetUserPersonalData(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, personalData.Name, user1.Name)
+	assert.Equal(t, personalData.Email, user1.Email)
+	// the name and email are neither in the public record nor in the transaction args
+	assert.NotContains(t, string(Stub.State[user1.ID]), user1.Name)
+	assert.NotContains(t, string(Stub.State[user1.ID]), user1.Email)
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("CreateUser"), []byte(user2.ID), []byte(user2.Name), []byte(user2.Email)})
+	assert.NotEqual(t, res.Status, int32(shim.OK))
 }
 
 func Test_UpdateUser(t *testing.T) {
@@ -119,7 +137,7 @@ func Test_UpdateUser(t *testing.T) {
 		t.FailNow()
 	}
 
-	MockUpdateUser(user1.ID, "change name", "change email")
+	MockUpdateUser(user1.ID, "change name", "change.email@g.com")
 
 	userJson, err := MockGetUser(user1.ID)
 	if err != nil {
@@ -127,8 +145,12 @@ func Test_UpdateUser(t *testing.T) {
 	}
 
 	assert.Equal(t, userJson.ID, user1.ID)
-	assert.Equal(t, userJson.Name, "change name")
-	assert.Equal(t, userJson.Email, "change email")
+	assert.NotEqual(t, userJson.UpdatedAt, "")
+
+	personalData, err := MockGetUserPersonalData(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, personalData.Name, "change name")
+	assert.Equal(t, personalData.Email, "change.email@g.com")
 
 }
 
@@ -177,19 +199,7 @@ func MockUserExists(id string) (bool, error) {
 }
 
 func MockCreateUser(id string, name string, email string) error {
-	res := Stub.MockInvoke("uuid",
-		[][]byte{
-			[]byte("CreateUser"),
-			[]byte(id),
-			[]byte(name),
-			[]byte(email),
-		})
-
-	if res.Status != shim.OK {
-		fmt.Println("CreateUser failed", string(res.Message))
-		return errors.New("CreateUser error")
-	}
-	return nil
+	return MockCreateUserWithTransient(id, name, email, nil)
 }
 
 func MockGetUser(id string) (*smartcontract.User, error) {
@@ -208,13 +218,16 @@ func MockGetUser(id string) (*smartcontract.User, error) {
 }
 
 func MockUpdateUser(id string, name string, email string) error {
-	res := Stub.MockInvoke("uuid",
+	return MockUpdateUserWithTransient(id, name, email, nil)
+}
+
+func MockUpdateUserWithTransient(id string, name string, email string, transient map[string][]byte) error {
+	res := MockInvokeWithTransient("uuid",
 		[][]byte{
 			[]byte("UpdateUser"),
 			[]byte(id),
-			[]byte(name),
-			[]byte(email),
-		})
+		},
+		withPersonalData(name, email, transient))
 	if res.Status != shim.OK {
 		fmt.Println("UpdateUser failed", string(res.Message))
 		return errors.New("UpdateUser error")
@@ -223,11 +236,12 @@ func MockUpdateUser(id string, name string, email string) error {
 }
 
 func MockDeleteUser(id string) error {
-	res := Stub.MockInvoke("uuid",
+	res := MockInvokeWithTransient("uuid",
 		[][]byte{
 			[]byte("DeleteUser"),
 			[]byte(id),
-		})
+		},
+		nil)
 	if res.Status != shim.OK {
 		fmt.Println("DeleteUser failed", string(res.Message))
 		return errors.New("DeleteUser error")
@@ -255,13 +269,13 @@ func Test_CreateTransaction(t *testing.T) {
 	if err != nil {
 		t.FailNow()
 	}
-	result1, err := MockCreateTransaction(user1.ID, transaction1.Hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	result1, err := MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
 	if err != nil {
 		fmt.Println("CreateTransaction User", err)
 	}
 	fmt.Println("CreateTransaction transaction1", result1)
 
-	result2, err := MockCreateTransaction(user1.ID, transaction2.Hash, transaction2.Amount, transaction2.Currency, transaction2.Date, transaction1.BankId)
+	result2, err := MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction2), transaction2.Amount, transaction2.Currency, transaction2.Date, transaction1.BankId)
 	if err != nil {
 		fmt.Println("CreateTransaction User", err)
 	}
@@ -274,10 +288,17 @@ func Test_CreateTransaction(t *testing.T) {
 	
 	fmt.Println(user)
 	assert.Equal(t, len(user.Transactions), 2)
+	assert.Equal(t, user.Transactions[0].Date, transaction1.Date)
+	assert.NotEqual(t, user.Transactions[0].CreatedAt, "")
 
 }
 
-func MockCreateTransaction(userId string, hash string, amount string, currency string, date string, bankId string) (bool, error) {
+func MockCreateTransaction(userId string, hash string, amount string, currency string, date string, bankId string) (string, error) {
+	transaction := smartcontract.Transaction{Amount: amount, Currency: currency, Date: date, BankId: bankId}
+	return MockCreateSignedTransaction(userId, hash, amount, currency, date, bankId, BankSignature(userId, transaction))
+}
+
+func MockCreateSignedTransaction(userId string, hash string, amount string, currency string, date string, bankId string, signature string) (string, error) {
 	res := Stub.MockInvoke("uuid",
 		[][]byte{
 			[]byte("CreateTransaction"),
@@ -287,14 +308,14 @@ func MockCreateTransaction(userId string, hash string, amount string, currency s
 			[]byte(currency),
 			[]byte(date),
 			[]byte(bankId),
+			[]byte(""),
+			[]byte(signature),
 		})
 	if res.Status != shim.OK {
 		fmt.Println("CreateTransaction failed", string(res.Message))
-		return false, errors.New("CreateTransaction error")
+		return "", errors.New("CreateTransaction error")
 	}
-	var result bool = false
-	json.Unmarshal(res.Payload, &result)
-	return result, nil
+	return string(res.Payload), nil
 }
 
 // Part 2
@@ -312,34 +333,30 @@ func Test_GetUserByTransactionHash(t *testing.T) {
 		t.FailNow()
 	}
 
-	result1, err := MockCreateTransaction(user1.ID, transaction1.Hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	result1, err := MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
 	if err != nil {
 		fmt.Println("CreateTransaction User", err)
 	}
 	fmt.Println("CreateTransaction transaction1", result1)
 
-	result2, err := MockCreateTransaction(user2.ID, transaction2.Hash, transaction2.Amount, transaction2.Currency, transaction2.Date, transaction1.BankId)
+	result2, err := MockCreateTransaction(user2.ID, TxHash(user2.ID, transaction2), transaction2.Amount, transaction2.Currency, transaction2.Date, transaction1.BankId)
 	if err != nil {
 		fmt.Println("CreateTransaction User", err)
 	}
 	fmt.Println("CreateTransaction transaction2", result2)
 
-	mockUser1, err := MockGetUserByTransactionHash(transaction1.Hash)
+	mockUser1, err := MockGetUserByTransactionHash(TxHash(user1.ID, transaction1))
 	if err != nil {
 		fmt.Println("get User error", err)
 	}
 
-	mockUser2, err := MockGetUserByTransactionHash(transaction2.Hash)
+	mockUser2, err := MockGetUserByTransactionHash(TxHash(user2.ID, transaction2))
 	if err != nil {
 		fmt.Println("get User error", err)
 	}
 	
 	assert.Equal(t, mockUser1.ID, user1.ID)
-	assert.Equal(t, mockUser1.Name, user1.Name)
-	assert.Equal(t, mockUser1.Email, user1.Email)
 	assert.Equal(t, mockUser2.ID, user2.ID)
-	assert.Equal(t, mockUser2.Name, user2.Name)
-	assert.Equal(t, mockUser2.Email, user2.Email)
 
 
 }
@@ -402,13 +419,13 @@ func Test_BankTransactionCount(t *testing.T) {
 	if err != nil {
 		t.FailNow()
 	}
-	result1, err := MockCreateTransaction(user1.ID, transaction1.Hash, transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+	result1, err := MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
 	if err != nil {
 		fmt.Println("CreateTransaction User", err)
 	}
 	fmt.Println("CreateTransaction transaction1", result1)
 
-	result2, err := MockCreateTransaction(user1.ID, transaction2.Hash, transaction2.Amount, transaction2.Currency, transaction2.Date, transaction1.BankId)
+	result2, err := MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction2), transaction2.Amount, transaction2.Currency, transaction2.Date, transaction1.BankId)
 	if err != nil {
 		fmt.Println("CreateTransaction User", err)
 	}
@@ -421,5 +438,7 @@ func Test_BankTransactionCount(t *testing.T) {
 	
 	fmt.Println(bank)
 	assert.Equal(t, bank.TransactionCount, 2)
+	assert.NotEqual(t, bank.CreatedAt, "")
+	assert.NotEqual(t, bank.UpdatedAt, "")
 
 }
\ No newline at end of file
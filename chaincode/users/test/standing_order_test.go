@@ -0,0 +1,225 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StandingOrders(t *testing.T) {
+	fmt.Println("Test_StandingOrders-----------------")
+	NewStub()
+	MockAddUserMSP("Org3MSP")
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+	today := time.Now().UTC().Format("2006-01-02")
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	nextWeek := time.Now().UTC().AddDate(0, 0, 7).Format("2006-01-02")
+
+	err := MockSetBankMSPID("04231910", "Org2MSP")
+	if err != nil {
+		t.FailNow()
+	}
+
+	_, err = MockCreateStandingOrder("order1", user1.ID, "100", "USD", "04231910", smartcontract.ScheduleDaily)
+	assert.NotNil(t, err)
+	MockSetCreator("Org2MSP", "bank")
+	_, err = MockCreateStandingOrder("order1", user1.ID, "100", "USD", "04231910", "yearly")
+	assert.NotNil(t, err)
+	_, err = MockCreateStandingOrder("order1", user1.ID, "0", "USD", "04231910", smartcontract.ScheduleDaily)
+	assert.NotNil(t, err)
+	_, err = MockCreateStandingOrder("order1", user1.ID, "100", "USD", "04231910", smartcontract.ScheduleDaily)
+	assert.Nil(t, err)
+	_, err = MockCreateStandingOrder("order2", user1.ID, "25", "USD", "04231910", smartcontract.ScheduleWeekly)
+	assert.Nil(t, err)
+	_, err = MockCreateStandingOrder("order3", user2.ID, "40", "NTD", "04231910", smartcontract.ScheduleMonthly)
+	assert.Nil(t, err)
+
+	_, err = MockExecuteDueStandingOrders(today)
+	assert.NotNil(t, err)
+	MockSetCreator("Org1MSP", "admin")
+	_, err = MockExecuteDueStandingOrders(tomorrow)
+	assert.NotNil(t, err)
+
+	execution, err := MockExecuteDueStandingOrders(today)
+	assert.Nil(t, err)
+	assert.Equal(t, len(execution.Transactions), 2)
+	assert.Equal(t, execution.Transactions[0].UserId, user1.ID)
+	assert.Equal(t, execution.Transactions[0].StandingOrderID, "order1")
+	assert.Equal(t, execution.Transactions[0].Date, today)
+	assert.Equal(t, execution.Transactions[1].UserId, user2.ID)
+	assert.Equal(t, execution.Pending, 1)
+
+	execution, err = MockExecuteDueStandingOrders(today)
+	assert.Nil(t, err)
+	assert.Equal(t, len(execution.Transactions), 1)
+	assert.Equal(t, execution.Transactions[0].StandingOrderID, "order2")
+	assert.Equal(t, execution.Pending, 0)
+
+	execution, err = MockExecuteDueStandingOrders(today)
+	assert.Nil(t, err)
+	assert.Equal(t, len(execution.Transactions), 0)
+
+	order, err := MockGetStandingOrder("order1")
+	assert.Nil(t, err)
+	assert.Equal(t, order.RunCount, 1)
+	assert.Equal(t, order.NextRunDate, tomorrow)
+	order, err = MockGetStandingOrder("order2")
+	assert.Nil(t, err)
+	assert.Equal(t, order.NextRunDate, nextWeek)
+
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 2)
+	verified, err := MockVerifyTransaction(user.Transactions[0].Hash)
+	assert.Nil(t, err)
+	assert.True(t, verified)
+	bank, err := MockGetBankByID("04231910")
+	assert.Nil(t, err)
+	assert.Equal(t, bank.TransactionCount, 3)
+
+	MockSetCreatorWithAttributes("Org3MSP", "someone", map[string]string{smartcontract.UserIDAttribute: user2.ID})
+	assert.NotNil(t, MockCancelStandingOrder("order1"))
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	assert.Nil(t, MockCancelStandingOrder("order1"))
+	assert.NotNil(t, MockCancelStandingOrder("order1"))
+	order, err = MockGetStandingOrder("order1")
+	assert.Nil(t, err)
+	assert.Equal(t, order.Status, smartcontract.StandingOrderCancelled)
+
+	// reading an order needs the consent of its user, cancelling it does not for its bank
+	MockSetCreatorWithAttributes("Org3MSP", "someone", map[string]string{smartcontract.UserIDAttribute: user2.ID})
+	_, err = MockGetStandingOrder("order2")
+	assert.NotNil(t, err)
+	MockSetCreator("Org2MSP", "bank")
+	_, err = MockGetStandingOrder("order2")
+	assert.NotNil(t, err)
+	assert.Nil(t, MockCancelStandingOrder("order2"))
+}
+
+func Test_StandingOrderAlertsInOneEvent(t *testing.T) {
+	fmt.Println("Test_StandingOrderAlertsInOneEvent-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+	today := time.Now().UTC().Format("2006-01-02")
+	if MockSetAMLRule("USD", "50", "") != nil {
+		t.FailNow()
+	}
+	for _, userId := range []string{user1.ID, user2.ID} {
+		_, err := MockCreateStandingOrder("order"+userId, userId, "100", "USD", "04231910", smartcontract.ScheduleDaily)
+		if err != nil {
+			t.FailNow()
+		}
+	}
+	for len(Stub.ChaincodeEventsChannel) > 0 {
+		<-Stub.ChaincodeEventsChannel
+	}
+
+	execution, err := MockExecuteDueStandingOrders(today)
+	assert.Nil(t, err)
+	assert.Equal(t, len(execution.Transactions), 2)
+	// Fabric keeps only the last event of a transaction, both alerts come in one
+	assert.Equal(t, len(Stub.ChaincodeEventsChannel), 1)
+	event := <-Stub.ChaincodeEventsChannel
+	assert.Equal(t, event.EventName, "AMLAlert")
+	var alerts []*smartcontract.Alert
+	assert.Nil(t, json.Unmarshal(event.Payload, &alerts))
+	assert.Equal(t, len(alerts), 2)
+	assert.Equal(t, alerts[0].UserId, user1.ID)
+	assert.Equal(t, alerts[1].UserId, user2.ID)
+}
+
+func Test_StandingOrderRunsAndFailures(t *testing.T) {
+	fmt.Println("Test_StandingOrderRunsAndFailures-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser("12", "Wang Xiao Ming", "wang@gmail.com")
+	today := time.Now().UTC().Format("2006-01-02")
+	err := MockSetBankMSPID("04231910", "Org2MSP")
+	if err != nil {
+		t.FailNow()
+	}
+
+	MockSetCreator("Org2MSP", "bank")
+	_, err = MockCreateStandingOrder("order1", "12", "100", "USD", "04231910", smartcontract.ScheduleDaily)
+	assert.Nil(t, err)
+	_, err = MockCreateStandingOrder("order2", user1.ID, "100", "USD", "04231910", smartcontract.ScheduleDaily)
+	assert.Nil(t, err)
+	_, err = MockCreateStandingOrder("order3", user1.ID, "100", "USD", "04231910", smartcontract.ScheduleDaily)
+	assert.Nil(t, err)
+
+	MockSetCreator("Org1MSP", "admin")
+	assert.Nil(t, MockAddWatchlistEntry("un-2", "Wang Xiaoming", "", smartcontract.WatchlistBlock))
+	execution, err := MockExecuteDueStandingOrders(today)
+	assert.Nil(t, err)
+	assert.Equal(t, len(execution.Failed), 1)
+	assert.Equal(t, execution.Failed[0].OrderID, "order1")
+	assert.Equal(t, execution.Failed[0].UserId, "12")
+	assert.Equal(t, len(execution.Transactions), 1)
+	assert.Equal(t, execution.Transactions[0].StandingOrderID, "order2")
+	assert.Equal(t, execution.Transactions[0].StandingOrderRun, 1)
+	assert.Equal(t, execution.Pending, 1)
+
+	// order3 records the same payment on the same day, its own hash tells it apart
+	execution, err = MockExecuteDueStandingOrders(today)
+	assert.Nil(t, err)
+	assert.Equal(t, len(execution.Transactions), 1)
+	assert.Equal(t, execution.Transactions[0].StandingOrderID, "order3")
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 2)
+	assert.NotEqual(t, user.Transactions[0].Hash, user.Transactions[1].Hash)
+
+	order, err := MockGetStandingOrder("order1")
+	assert.Nil(t, err)
+	assert.Equal(t, order.RunCount, 0)
+	assert.Equal(t, order.NextRunDate, today)
+}
+
+func MockCreateStandingOrder(txId string, userId string, amount string, currency string, bankId string, schedule string) (string, error) {
+	res := Stub.MockInvoke(txId, [][]byte{[]byte("CreateStandingOrder"), []byte(userId), []byte(amount), []byte(currency), []byte(bankId), []byte(schedule)})
+	if res.Status != shim.OK {
+		fmt.Println("CreateStandingOrder failed", string(res.Message))
+		return "", errors.New("CreateStandingOrder error")
+	}
+	return string(res.Payload), nil
+}
+
+func MockGetStandingOrder(id string) (*smartcontract.StandingOrder, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetStandingOrder"), []byte(id)})
+	if res.Status != shim.OK {
+		fmt.Println("GetStandingOrder failed", string(res.Message))
+		return nil, errors.New("GetStandingOrder error")
+	}
+	var order smartcontract.StandingOrder
+	json.Unmarshal(res.Payload, &order)
+	return &order, nil
+}
+
+func MockCancelStandingOrder(id string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("CancelStandingOrder"), []byte(id)})
+	if res.Status != shim.OK {
+		fmt.Println("CancelStandingOrder failed", string(res.Message))
+		return errors.New("CancelStandingOrder error")
+	}
+	return nil
+}
+
+func MockExecuteDueStandingOrders(asOf string) (*smartcontract.StandingOrderExecution, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ExecuteDueStandingOrders"), []byte(asOf)})
+	if res.Status != shim.OK {
+		fmt.Println("ExecuteDueStandingOrders failed", string(res.Message))
+		return nil, errors.New("ExecuteDueStandingOrders error")
+	}
+	var execution smartcontract.StandingOrderExecution
+	json.Unmarshal(res.Payload, &execution)
+	return &execution, nil
+}
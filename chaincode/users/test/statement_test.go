@@ -0,0 +1,99 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetUserStatement(t *testing.T) {
+	fmt.Println("Test_GetUserStatement-----------------")
+	NewStub()
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+
+	transactions := []smartcontract.Transaction{
+		{Amount: "50", Currency: "USD", Date: "2022-03-31", BankId: "04231910"},
+		{Amount: "200", Currency: "USD", Date: "2022-04-14", BankId: "04231910"},
+		{Amount: "12.5", Currency: "USD", Date: "2022-04-20", BankId: "03750168"},
+		{Amount: "500", Currency: "NTD", Date: "2022-04-30", BankId: "04231910"},
+		{Amount: "70", Currency: "NTD", Date: "2022-05-01", BankId: "04231910"},
+	}
+	for _, transaction := range transactions {
+		_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction), transaction.Amount, transaction.Currency, transaction.Date, transaction.BankId)
+		if err != nil {
+			t.FailNow()
+		}
+	}
+
+	statement, err := MockGetUserStatement(user1.ID, "2022-04")
+	assert.Nil(t, err)
+	assert.Equal(t, statement.OpeningCount, 1)
+	assert.Equal(t, statement.ClosingCount, 4)
+	assert.Equal(t, len(statement.Transactions), 3)
+	assert.Equal(t, statement.Totals, map[string]string{"USD": "212.5", "NTD": "500"})
+	assert.Equal(t, len(statement.Banks), 2)
+	assert.Equal(t, statement.Banks[0].BankId, "03750168")
+	assert.Equal(t, statement.Banks[0].Count, 1)
+	assert.Equal(t, statement.Banks[1].Totals, map[string]string{"USD": "200", "NTD": "500"})
+
+	_, err = MockGetUserStatement(user1.ID, "2022-4")
+	assert.NotNil(t, err)
+}
+
+func Test_GetUserStatementWithArchive(t *testing.T) {
+	fmt.Println("Test_GetUserStatementWithArchive-----------------")
+	NewStub()
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+	transactions := []smartcontract.Transaction{
+		{Amount: "50", Currency: "USD", Date: "2022-03-31", BankId: "04231910"},
+		{Amount: "20", Currency: "USD", Date: "2022-03-15", BankId: "04231910"},
+		{Amount: "200", Currency: "USD", Date: "2022-04-14", BankId: "04231910"},
+	}
+	for _, transaction := range transactions {
+		_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction), transaction.Amount, transaction.Currency, transaction.Date, transaction.BankId)
+		if err != nil {
+			t.FailNow()
+		}
+	}
+	signingKey, publicKey := NewFieldSigningKey()
+	err = MockRegisterArchiveKey(string(publicKey))
+	if err != nil {
+		t.FailNow()
+	}
+	_, err = MockArchiveTransactions(user1.ID, "2022-04-01", signingKey)
+	if err != nil {
+		t.FailNow()
+	}
+
+	statement, err := MockGetUserStatement(user1.ID, "2022-04")
+	assert.Nil(t, err)
+	assert.Equal(t, statement.OpeningCount, 2)
+	assert.Equal(t, statement.ClosingCount, 3)
+	assert.Equal(t, len(statement.Transactions), 1)
+
+	_, err = MockGetUserStatement(user1.ID, "2022-03")
+	assert.NotNil(t, err)
+}
+
+func MockGetUserStatement(userId string, month string) (*smartcontract.Statement, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetUserStatement"), []byte(userId), []byte(month)})
+	if res.Status != shim.OK {
+		fmt.Println("GetUserStatement failed", string(res.Message))
+		return nil, errors.New("GetUserStatement error")
+	}
+	var statement smartcontract.Statement
+	json.Unmarshal(res.Payload, &statement)
+	return &statement, nil
+}
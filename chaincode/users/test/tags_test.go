@@ -0,0 +1,102 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TagTransaction(t *testing.T) {
+	fmt.Println("Test_TagTransaction-----------------")
+	NewStub()
+	MockAddUserMSP("Org3MSP")
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	transactions := []smartcontract.Transaction{
+		{Amount: "3000", Currency: "USD", Date: "2022-04-01", BankId: "04231910"},
+		{Amount: "800", Currency: "USD", Date: "2022-04-05", BankId: "04231910"},
+		{Amount: "50", Currency: "USD", Date: "2022-04-20", BankId: "03750168"},
+	}
+	var hashes []string
+	for _, transaction := range transactions {
+		hash := TxHash(user1.ID, transaction)
+		_, err := MockCreateTransaction(user1.ID, hash, transaction.Amount, transaction.Currency, transaction.Date, transaction.BankId)
+		if err != nil {
+			t.FailNow()
+		}
+		hashes = append(hashes, hash)
+	}
+
+	assert.Nil(t, MockSetBankMSPID("04231910", "Org2MSP"))
+	assert.Nil(t, MockSetBankMSPID("03750168", "Org4MSP"))
+
+	assert.NotNil(t, MockTagTransaction(hashes[0], "monthly"))
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	assert.Nil(t, MockTagTransaction(hashes[0], "Monthly, payroll,monthly"))
+	assert.Nil(t, MockTagTransaction(hashes[1], "monthly"))
+	assert.NotNil(t, MockTagTransaction(hashes[2], "not a tag!"))
+	assert.Nil(t, MockCategorizeTransaction(hashes[0], "salary"))
+	assert.NotNil(t, MockCategorizeTransaction(hashes[1], "rent & bills"))
+
+	MockSetCreator("Org4MSP", "other bank")
+	assert.NotNil(t, MockCategorizeTransaction(hashes[1], "rent"))
+	MockSetCreator("Org2MSP", "bank")
+	assert.Nil(t, MockCategorizeTransaction(hashes[1], "rent"))
+	assert.Nil(t, MockTagTransaction(hashes[0], "payroll"))
+
+	MockSetCreator("Org1MSP", "admin")
+	tagged, err := MockListTransactionsByTag(user1.ID, "monthly")
+	assert.Nil(t, err)
+	assert.Equal(t, len(tagged), 1)
+	assert.Equal(t, tagged[0].Hash, hashes[1])
+	tagged, err = MockListTransactionsByTag(user1.ID, "payroll")
+	assert.Nil(t, err)
+	assert.Equal(t, len(tagged), 1)
+	assert.Equal(t, tagged[0].Tags, []string{"payroll"})
+	assert.Equal(t, tagged[0].Category, "salary")
+
+	statement, err := MockGetUserStatement(user1.ID, "2022-04")
+	assert.Nil(t, err)
+	assert.Equal(t, len(statement.Categories), 3)
+	assert.Equal(t, statement.Categories[0].Category, "rent")
+	assert.Equal(t, statement.Categories[1].Totals, map[string]string{"USD": "3000"})
+	assert.Equal(t, statement.Categories[2].Category, smartcontract.CategoryUncategorized)
+
+	MockSetCreator("Org2MSP", "bank")
+	_, err = MockListTransactionsByTag(user1.ID, "monthly")
+	assert.NotNil(t, err)
+}
+
+func MockTagTransaction(hash string, tags string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("TagTransaction"), []byte(hash), []byte(tags)})
+	if res.Status != shim.OK {
+		fmt.Println("TagTransaction failed", string(res.Message))
+		return errors.New("TagTransaction error")
+	}
+	return nil
+}
+
+func MockCategorizeTransaction(hash string, category string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("CategorizeTransaction"), []byte(hash), []byte(category)})
+	if res.Status != shim.OK {
+		fmt.Println("CategorizeTransaction failed", string(res.Message))
+		return errors.New("CategorizeTransaction error")
+	}
+	return nil
+}
+
+func MockListTransactionsByTag(userId string, tag string) ([]smartcontract.Transaction, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("ListTransactionsByTag"), []byte(userId), []byte(tag)})
+	if res.Status != shim.OK {
+		fmt.Println("ListTransactionsByTag failed", string(res.Message))
+		return nil, errors.New("ListTransactionsByTag error")
+	}
+	var transactions []smartcontract.Transaction
+	json.Unmarshal(res.Payload, &transactions)
+	return transactions, nil
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LinkTokenAccount(t *testing.T) {
+	fmt.Println("Test_LinkTokenAccount-----------------")
+	NewStub()
+	MockAddUserMSP("Org3MSP")
+	tokenStub := NewTokenStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+
+	_, err := MockLinkTokenAccount(user1.ID)
+	assert.NotNil(t, err)
+	MockSetCreatorWithAttributes("Org3MSP", "owner", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	account, err := MockLinkTokenAccount(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, account.UserId, user1.ID)
+	assert.NotEqual(t, account.AccountID, "")
+	_, err = MockLinkTokenAccount(user2.ID)
+	assert.NotNil(t, err)
+
+	_, err = MockGetUserTokenBalance(user1.ID)
+	assert.NotNil(t, err)
+	tokenStub.MockInvoke("uuid", [][]byte{[]byte("SetBalance"), []byte(account.AccountID), []byte("250")})
+	balance, err := MockGetUserTokenBalance(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, balance, 250)
+
+	MockSetCreator("Org1MSP", "admin")
+	user, err := MockGetUserByTokenAccount(account.AccountID)
+	assert.Nil(t, err)
+	assert.Equal(t, user.ID, user1.ID)
+	_, err = MockGetUserByTokenAccount("unknown")
+	assert.NotNil(t, err)
+
+	MockSetCreator("Org2MSP", "bank")
+	_, err = MockGetUserTokenBalance(user1.ID)
+	assert.NotNil(t, err)
+
+	MockSetCreator("Org1MSP", "admin")
+	_, err = MockEraseUserPersonalData(user1.ID)
+	assert.Nil(t, err)
+	_, err = MockGetUserByTokenAccount(account.AccountID)
+	assert.NotNil(t, err)
+}
+
+func MockLinkTokenAccount(userId string) (*smartcontract.TokenAccount, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("LinkTokenAccount"), []byte(userId)})
+	if res.Status != shim.OK {
+		fmt.Println("LinkTokenAccount failed", string(res.Message))
+		return nil, errors.New("LinkTokenAccount error")
+	}
+	var account smartcontract.TokenAccount
+	json.Unmarshal(res.Payload, &account)
+	return &account, nil
+}
+
+func MockGetUserByTokenAccount(accountId string) (*smartcontract.User, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetUserByTokenAccount"), []byte(accountId)})
+	if res.Status != shim.OK {
+		fmt.Println("GetUserByTokenAccount failed", string(res.Message))
+		return nil, errors.New("GetUserByTokenAccount error")
+	}
+	var user smartcontract.User
+	json.Unmarshal(res.Payload, &user)
+	return &user, nil
+}
+
+func MockGetUserTokenBalance(userId string) (int, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetUserTokenBalance"), []byte(userId)})
+	if res.Status != shim.OK {
+		fmt.Println("GetUserTokenBalance failed", string(res.Message))
+		return 0, errors.New("GetUserTokenBalance error")
+	}
+	return strconv.Atoi(string(res.Payload))
+}
@@ -0,0 +1,162 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ListTransactionsByDateRangeAndBank(t *testing.T) {
+	fmt.Println("Test_ListTransactionsByDateRangeAndBank-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+
+	transactions := []struct {
+		userId string
+		smartcontract.Transaction
+	}{
+		{user1.ID, smartcontract.Transaction{Amount: "1", Currency: "USD", Date: "2022-04-01", BankId: "04231910"}},
+		{user2.ID, smartcontract.Transaction{Amount: "2", Currency: "USD", Date: "2022-04-02", BankId: "03750168"}},
+		{user1.ID, smartcontract.Transaction{Amount: "3", Currency: "USD", Date: "2022-04-02", BankId: "04231910"}},
+		{user2.ID, smartcontract.Transaction{Amount: "4", Currency: "USD", Date: "2022-04-05", BankId: "04231910"}},
+		{user1.ID, smartcontract.Transaction{Amount: "5", Currency: "USD", Date: "2022-05-01", BankId: "04231910"}},
+	}
+	for _, transaction := range transactions {
+		_, err := MockCreateTransaction(transaction.userId, TxHash(transaction.userId, transaction.Transaction), transaction.Amount, transaction.Currency, transaction.Date, transaction.BankId)
+		if err != nil {
+			t.FailNow()
+		}
+	}
+
+	page, err := MockListTransactionsByDateRange("2022-04-01", "2022-04-30", "", 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Transactions), 4)
+	assert.Equal(t, page.Bookmark, "")
+	assert.Equal(t, page.Transactions[0].Amount, "1")
+	assert.Equal(t, page.Transactions[3].UserId, user2.ID)
+
+	var amounts []string
+	bookmark := ""
+	for {
+		page, err = MockListTransactionsByBank("04231910", "2022-04-01", "2022-04-30", smartcontract.DateFieldBusiness, 2, bookmark)
+		assert.Nil(t, err)
+		for _, transaction := range page.Transactions {
+			amounts = append(amounts, transaction.Amount)
+		}
+		if page.Bookmark == "" {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+	assert.Equal(t, amounts, []string{"1", "3", "4"})
+
+	_, err = MockListTransactionsByDateRange("2022-04-30", "2022-04-01", "", 10, "")
+	assert.NotNil(t, err)
+	_, err = MockListTransactionsByDateRange("2020-01-01", "2022-04-01", "", 10, "")
+	assert.NotNil(t, err)
+	_, err = MockListTransactionsByDateRange("2022-04-01", "2022-04-30", "settled_at", 10, "")
+	assert.NotNil(t, err)
+}
+
+func Test_ListTransactionsByRecordedDate(t *testing.T) {
+	fmt.Println("Test_ListTransactionsByRecordedDate-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+
+	backdated := smartcontract.Transaction{Amount: "7", Currency: "USD", Date: "2022-04-01", BankId: "04231910"}
+	_, err := MockCreateTransaction(user1.ID, TxHash(user1.ID, backdated), backdated.Amount, backdated.Currency, backdated.Date, backdated.BankId)
+	if err != nil {
+		t.FailNow()
+	}
+
+	// MockStub stamps every transaction with the current time
+	today := time.Now().UTC().Format("2006-01-02")
+	page, err := MockListTransactionsByDateRange(today, today, smartcontract.DateFieldRecorded, 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Transactions), 1)
+	assert.Equal(t, page.Transactions[0].Date, "2022-04-01")
+	assert.Equal(t, page.Transactions[0].CreatedAt[:10], today)
+
+	page, err = MockListTransactionsByDateRange(today, today, smartcontract.DateFieldBusiness, 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Transactions), 0)
+
+	page, err = MockListTransactionsByBank("04231910", today, today, smartcontract.DateFieldRecorded, 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Transactions), 1)
+	page, err = MockListTransactionsByBank("03750168", today, today, smartcontract.DateFieldRecorded, 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Transactions), 0)
+}
+
+func Test_ListTransactionsAfterDeleteUser(t *testing.T) {
+	fmt.Println("Test_ListTransactionsAfterDeleteUser-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+	for _, userId := range []string{user1.ID, user2.ID} {
+		_, err := MockCreateTransaction(userId, TxHash(userId, transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, transaction1.BankId)
+		if err != nil {
+			t.FailNow()
+		}
+	}
+
+	assert.Nil(t, MockDeleteUser(user1.ID))
+	page, err := MockListTransactionsByDateRange(transaction1.Date, transaction1.Date, "", 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Transactions), 1)
+	assert.Equal(t, page.Transactions[0].UserId, user2.ID)
+	page, err = MockListTransactionsByBank(transaction1.BankId, transaction1.Date, transaction1.Date, "", 10, "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(page.Transactions), 1)
+	_, err = MockGetUserByTransactionHash(TxHash(user1.ID, transaction1))
+	assert.NotNil(t, err)
+}
+
+func MockListTransactionsByDateRange(startDate string, endDate string, dateField string, pageSize int, bookmark string) (*smartcontract.TransactionPage, error) {
+	res := Stub.MockInvoke("uuid",
+		[][]byte{
+			[]byte("ListTransactionsByDateRange"),
+			[]byte(startDate),
+			[]byte(endDate),
+			[]byte(dateField),
+			[]byte(strconv.Itoa(pageSize)),
+			[]byte(bookmark),
+		})
+	if res.Status != shim.OK {
+		fmt.Println("ListTransactionsByDateRange failed", string(res.Message))
+		return nil, errors.New("ListTransactionsByDateRange error")
+	}
+	var page smartcontract.TransactionPage
+	json.Unmarshal(res.Payload, &page)
+	return &page, nil
+}
+
+func MockListTransactionsByBank(bankId string, startDate string, endDate string, dateField string, pageSize int, bookmark string) (*smartcontract.TransactionPage, error) {
+	res := Stub.MockInvoke("uuid",
+		[][]byte{
+			[]byte("ListTransactionsByBank"),
+			[]byte(bankId),
+			[]byte(startDate),
+			[]byte(endDate),
+			[]byte(dateField),
+			[]byte(strconv.Itoa(pageSize)),
+			[]byte(bookmark),
+		})
+	if res.Status != shim.OK {
+		fmt.Println("ListTransactionsByBank failed", string(res.Message))
+		return nil, errors.New("ListTransactionsByBank error")
+	}
+	var page smartcontract.TransactionPage
+	json.Unmarshal(res.Payload, &page)
+	return &page, nil
+}
@@ -0,0 +1,172 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TransferBetweenUsers(t *testing.T) {
+	fmt.Println("Test_TransferBetweenUsers-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+
+	transferId, err := MockTransferBetweenUsers("tx1", user1.ID, user2.ID, "100.5", "NTD", "04231910", "rent")
+	assert.Nil(t, err)
+	assert.Equal(t, transferId, "tx1")
+	_, err = MockTransferBetweenUsers("tx2", user2.ID, user1.ID, "30", "NTD", "04231910", "")
+	assert.Nil(t, err)
+	_, err = MockTransferBetweenUsers("tx3", user2.ID, user1.ID, "5", "USD", "03750168", "")
+	assert.Nil(t, err)
+
+	balances, err := MockGetUserBalances(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, balances, []*smartcontract.Balance{{Currency: "NTD", Amount: "-70.5"}, {Currency: "USD", Amount: "5"}})
+	balances, err = MockGetUserBalances(user2.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, balances, []*smartcontract.Balance{{Currency: "NTD", Amount: "70.5"}, {Currency: "USD", Amount: "-5"}})
+
+	from, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	to, err := MockGetUser(user2.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, from.Transfers[0].TransferID, to.Transfers[0].TransferID)
+	assert.Equal(t, from.Transfers[0].Direction, smartcontract.EntryDebit)
+	assert.Equal(t, to.Transfers[0].Direction, smartcontract.EntryCredit)
+	assert.Equal(t, to.Transfers[0].Counterparty, user1.ID)
+	assert.Equal(t, to.Transfers[0].Balance, "100.5")
+
+	transfer, err := MockGetTransfer("tx1")
+	assert.Nil(t, err)
+	assert.Equal(t, transfer.FromUserId, user1.ID)
+	assert.Equal(t, transfer.Reference, "rent")
+}
+
+func Test_TransferBetweenUsersRejected(t *testing.T) {
+	fmt.Println("Test_TransferBetweenUsersRejected-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+
+	_, err := MockTransferBetweenUsers("tx1", user1.ID, user1.ID, "1", "NTD", "04231910", "")
+	assert.NotNil(t, err)
+	_, err = MockTransferBetweenUsers("tx2", user1.ID, user2.ID, "0", "NTD", "04231910", "")
+	assert.NotNil(t, err)
+	_, err = MockTransferBetweenUsers("tx3", user1.ID, "999", "1", "NTD", "04231910", "")
+	assert.NotNil(t, err)
+	_, err = MockTransferBetweenUsers("tx4", user1.ID, user2.ID, "1", "NTD", "12345678", "")
+	assert.NotNil(t, err)
+
+	balances, err := MockGetUserBalances(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(balances), 0)
+}
+
+func Test_TransferBetweenUsersAuthorization(t *testing.T) {
+	fmt.Println("Test_TransferBetweenUsersAuthorization-----------------")
+	NewStub()
+	MockAddUserMSP("Org3MSP")
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+	err := MockSetBankMSPID("04231910", "Org2MSP")
+	if err != nil {
+		t.FailNow()
+	}
+
+	MockSetCreator("Org3MSP", "intruder")
+	_, err = MockTransferBetweenUsers("tx1", user1.ID, user2.ID, "1000", "USD", "04231910", "")
+	assert.NotNil(t, err)
+	MockSetCreatorWithAttributes("Org3MSP", "payee", map[string]string{smartcontract.UserIDAttribute: user2.ID})
+	_, err = MockTransferBetweenUsers("tx2", user1.ID, user2.ID, "1000", "USD", "04231910", "")
+	assert.NotNil(t, err)
+	_, err = MockGetTransfer("tx2")
+	assert.NotNil(t, err)
+
+	MockSetCreatorWithAttributes("Org3MSP", "payer", map[string]string{smartcontract.UserIDAttribute: user1.ID})
+	_, err = MockTransferBetweenUsers("tx3", user1.ID, user2.ID, "10", "USD", "04231910", "")
+	assert.Nil(t, err)
+	MockSetCreator("Org2MSP", "teller")
+	_, err = MockTransferBetweenUsers("tx4", user2.ID, user1.ID, "5", "USD", "04231910", "")
+	assert.Nil(t, err)
+	_, err = MockGetTransfer("tx4")
+	assert.NotNil(t, err)
+
+	MockSetCreator("Org1MSP", "admin")
+	balances, err := MockGetUserBalances(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, balances, []*smartcontract.Balance{{Currency: "USD", Amount: "-5"}})
+}
+
+func Test_TransferBetweenUsersScreened(t *testing.T) {
+	fmt.Println("Test_TransferBetweenUsersScreened-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	MockCreateUser(user2.ID, user2.Name, user2.Email)
+	err := MockSetAMLRule("USD", "500", "")
+	if err != nil {
+		t.FailNow()
+	}
+	err = MockAddWatchlistEntry("SDN-1", user2.Name, "", smartcontract.WatchlistBlock)
+	if err != nil {
+		t.FailNow()
+	}
+
+	_, err = MockTransferBetweenUsers("tx1", user1.ID, user2.ID, "10", "USD", "04231910", "")
+	assert.NotNil(t, err)
+	assert.Nil(t, MockRemoveWatchlistEntry("SDN-1"))
+	_, err = MockTransferBetweenUsers("tx2", user1.ID, user2.ID, "600", "USD", "04231910", "")
+	assert.Nil(t, err)
+
+	alerts, err := MockListAlerts(smartcontract.AlertStatusOpen)
+	assert.Nil(t, err)
+	assert.Equal(t, len(alerts), 1)
+	assert.Equal(t, alerts[0].ID, "tx2_"+smartcontract.AlertRuleThreshold)
+	assert.Equal(t, alerts[0].UserId, user1.ID)
+}
+
+func MockTransferBetweenUsers(txId string, fromUserId string, toUserId string, amount string, currency string, bankId string, reference string) (string, error) {
+	res := Stub.MockInvoke(txId,
+		[][]byte{
+			[]byte("TransferBetweenUsers"),
+			[]byte(fromUserId),
+			[]byte(toUserId),
+			[]byte(amount),
+			[]byte(currency),
+			[]byte(bankId),
+			[]byte(reference),
+		})
+	if res.Status != shim.OK {
+		fmt.Println("TransferBetweenUsers failed", string(res.Message))
+		return "", errors.New("TransferBetweenUsers error")
+	}
+	return string(res.Payload), nil
+}
+
+func MockGetUserBalances(userId string) ([]*smartcontract.Balance, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetUserBalances"), []byte(userId)})
+	if res.Status != shim.OK {
+		fmt.Println("GetUserBalances failed", string(res.Message))
+		return nil, errors.New("GetUserBalances error")
+	}
+	var balances []*smartcontract.Balance
+	json.Unmarshal(res.Payload, &balances)
+	return balances, nil
+}
+
+func MockGetTransfer(transferId string) (*smartcontract.Transfer, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("GetTransfer"), []byte(transferId)})
+	if res.Status != shim.OK {
+		fmt.Println("GetTransfer failed", string(res.Message))
+		return nil, errors.New("GetTransfer error")
+	}
+	var transfer smartcontract.Transfer
+	json.Unmarshal(res.Payload, &transfer)
+	return &transfer, nil
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"encoding/json"
+	"sort"
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// transientStub serves a transient map on top of Stub, since shimtest.MockStub
+// always returns an empty one
+type transientStub struct {
+	*shimtest.MockStub
+	args      [][]byte
+	transient map[string][]byte
+}
+
+func (stub *transientStub) GetArgs() [][]byte {
+	return stub.args
+}
+
+func (stub *transientStub) GetStringArgs() []string {
+	strargs := make([]string, 0, len(stub.args))
+	for _, barg := range stub.args {
+		strargs = append(strargs, string(barg))
+	}
+	return strargs
+}
+
+func (stub *transientStub) GetFunctionAndParameters() (string, []string) {
+	allargs := stub.GetStringArgs()
+	if len(allargs) == 0 {
+		return "", []string{}
+	}
+	return allargs[0], allargs[1:]
+}
+
+func (stub *transientStub) GetTransient() (map[string][]byte, error) {
+	return stub.transient, nil
+}
+
+// PurgePrivateData stands in for the call added to the shim in Fabric 2.5, which MockStub
+// predates
+func (stub *transientStub) PurgePrivateData(collection string, key string) error {
+	delete(stub.PvtState[collection], key)
+	return nil
+}
+
+// GetPrivateDataByRange stands in for the range query MockStub does not implement
+func (stub *transientStub) GetPrivateDataByRange(collection string, startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	var keys []string
+	for key := range stub.PvtState[collection] {
+		if key >= startKey && (endKey == "" || key < endKey) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	iterator := &privateDataIterator{}
+	for _, key := range keys {
+		iterator.results = append(iterator.results, &queryresult.KV{Namespace: stub.Name, Key: key, Value: stub.PvtState[collection][key]})
+	}
+	return iterator, nil
+}
+
+// privateDataIterator iterates over the results of GetPrivateDataByRange
+type privateDataIterator struct {
+	results []*queryresult.KV
+}
+
+func (iterator *privateDataIterator) HasNext() bool {
+	return len(iterator.results) > 0
+}
+
+func (iterator *privateDataIterator) Next() (*queryresult.KV, error) {
+	result := iterator.results[0]
+	iterator.results = iterator.results[1:]
+	return result, nil
+}
+
+func (iterator *privateDataIterator) Close() error {
+	return nil
+}
+
+// withPersonalData returns transient with the name and email set in the PersonalData
+// under PersonalDataTransientKey, adding the entry when missing
+func withPersonalData(name string, email string, transient map[string][]byte) map[string][]byte {
+	var personalData smartcontract.PersonalData
+	if personalDataJson, ok := transient[smartcontract.PersonalDataTransientKey]; ok {
+		json.Unmarshal(personalDataJson, &personalData)
+	}
+	personalData.Name = name
+	personalData.Email = email
+	personalDataJson, _ := json.Marshal(personalData)
+	merged := map[string][]byte{smartcontract.PersonalDataTransientKey: personalDataJson}
+	for key, value := range transient {
+		if key != smartcontract.PersonalDataTransientKey {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// MockInvokeWithTransient invokes the chaincode like Stub.MockInvoke with transient
+// as the proposal's transient map
+func MockInvokeWithTransient(uuid string, args [][]byte, transient map[string][]byte) pb.Response {
+	stub := &transientStub{MockStub: Stub, args: args, transient: transient}
+	Stub.MockTransactionStart(uuid)
+	res := Scc.Invoke(stub)
+	Stub.MockTransactionEnd(uuid)
+	return res
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserMembership(t *testing.T) {
+	fmt.Println("Test_UserMembership-----------------")
+	NewStub()
+	MockCreateUser(user1.ID, user1.Name, user1.Email)
+	owner := map[string]string{smartcontract.UserIDAttribute: user1.ID}
+
+	MockSetCreatorWithAttributes("Org3MSP", "owner", owner)
+	assert.NotNil(t, MockGrantConsent(user1.ID, "Org2MSP", "2099-12-31"))
+	assert.NotNil(t, MockAddUserMSP("Org3MSP"))
+
+	MockSetCreator("Org1MSP", "admin")
+	assert.Nil(t, MockSetBankMSPID("04231910", "Org2MSP"))
+	assert.NotNil(t, MockAddUserMSP("Org2MSP"))
+	assert.Nil(t, MockAddUserMSP("Org3MSP"))
+	assert.NotNil(t, MockSetBankMSPID("03750168", "Org3MSP"))
+
+	MockSetCreatorWithAttributes("Org3MSP", "owner", owner)
+	assert.Nil(t, MockGrantConsent(user1.ID, "Org2MSP", "2099-12-31"))
+	MockSetCreatorWithAttributes("Org2MSP", "bank", owner)
+	assert.NotNil(t, MockRevokeConsent(user1.ID, "Org2MSP"))
+
+	MockSetCreator("Org1MSP", "admin")
+	assert.Nil(t, MockRemoveUserMSP("Org3MSP"))
+	assert.NotNil(t, MockRemoveUserMSP("Org3MSP"))
+	MockSetCreatorWithAttributes("Org3MSP", "owner", owner)
+	assert.NotNil(t, MockRevokeConsent(user1.ID, "Org2MSP"))
+}
+
+func MockAddUserMSP(mspId string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("AddUserMSP"), []byte(mspId)})
+	if res.Status != shim.OK {
+		fmt.Println("AddUserMSP failed", string(res.Message))
+		return errors.New("AddUserMSP error")
+	}
+	return nil
+}
+
+func MockRemoveUserMSP(mspId string) error {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("RemoveUserMSP"), []byte(mspId)})
+	if res.Status != shim.OK {
+		fmt.Println("RemoveUserMSP failed", string(res.Message))
+		return errors.New("RemoveUserMSP error")
+	}
+	return nil
+}
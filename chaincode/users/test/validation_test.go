@@ -0,0 +1,107 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"users/smartcontract"
+
+	"github.com/go-openapi/spec"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CreateUserValidation(t *testing.T) {
+	fmt.Println("Test_CreateUserValidation-----------------")
+	NewStub()
+
+	assert.NotNil(t, MockCreateUser("", user1.Name, user1.Email))
+	assert.NotNil(t, MockCreateUser("abc", user1.Name, user1.Email))
+	assert.NotNil(t, MockCreateUser(user1.ID, "", user1.Email))
+	assert.NotNil(t, MockCreateUser(user1.ID, strings.Repeat("名", 65), user1.Email))
+	assert.NotNil(t, MockCreateUser(user1.ID, user1.Name, "not an email"))
+
+	exists, err := MockUserExists(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, exists, false)
+
+	assert.Nil(t, MockCreateUser(user1.ID, "李小明", user1.Email))
+}
+
+func Test_CreateTransactionValidation(t *testing.T) {
+	fmt.Println("Test_CreateTransactionValidation-----------------")
+	NewStub()
+	err := MockCreateUser(user1.ID, user1.Name, user1.Email)
+	if err != nil {
+		t.FailNow()
+	}
+
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), "-200", transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.NotNil(t, err)
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), "1.000000001", transaction1.Currency, transaction1.Date, transaction1.BankId)
+	assert.NotNil(t, err)
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, "usd", transaction1.Date, transaction1.BankId)
+	assert.NotNil(t, err)
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, transaction1.Currency, "2022-02-30", transaction1.BankId)
+	assert.NotNil(t, err)
+	_, err = MockCreateTransaction(user1.ID, TxHash(user1.ID, transaction1), transaction1.Amount, transaction1.Currency, transaction1.Date, "1234")
+	assert.NotNil(t, err)
+
+	user, err := MockGetUser(user1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, len(user.Transactions), 0)
+}
+
+func Test_GetMetadataValidationRules(t *testing.T) {
+	fmt.Println("Test_GetMetadataValidationRules-----------------")
+	// contractapi reads the metadata next to the binary, here the test binary, where main
+	// installs it before creating the chaincode
+	path, err := smartcontract.InstallContractMetadata()
+	if err != nil {
+		t.FailNow()
+	}
+	defer os.RemoveAll(filepath.Dir(path))
+	installed, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	committed, err := ioutil.ReadFile(filepath.Join("..", metadata.MetadataFolder, metadata.MetadataFile))
+	assert.Nil(t, err)
+	assert.Equal(t, string(installed), string(committed), "run go generate in smartcontract")
+	NewStub()
+
+	published, err := MockGetMetadata()
+	assert.Nil(t, err)
+	var chaincodeMetadata metadata.ContractChaincodeMetadata
+	assert.Nil(t, json.Unmarshal([]byte(published), &chaincodeMetadata))
+	assert.Nil(t, metadata.ValidateAgainstSchema(chaincodeMetadata))
+	parameters := map[string]*spec.Schema{}
+	for _, transaction := range chaincodeMetadata.Contracts["SmartContract"].Transactions {
+		for _, parameter := range transaction.Parameters {
+			parameters[transaction.Name+"."+parameter.Name] = parameter.Schema
+		}
+	}
+	assert.Equal(t, int64(64), *parameters["SearchUsersByNamePrefix.prefix"].MaxLength)
+	assert.Equal(t, "date", parameters["GrantConsent.expiresOn"].Format)
+	assert.Equal(t, "^[A-Z]{3}$", parameters["CreateTransaction.currency"].Pattern)
+	assert.Equal(t, "^[0-9]{8}$", parameters["TransferBetweenUsers.bankId"].Pattern)
+	assert.Equal(t, "", parameters["CreateTransaction.signature"].Pattern)
+
+	// the published rules hold for transactions too
+	assert.NotNil(t, MockCreateUser("abc", user1.Name, user1.Email))
+	assert.Nil(t, MockCreateUser(user1.ID, user1.Name, user1.Email))
+}
+
+func MockGetMetadata() (string, error) {
+	res := Stub.MockInvoke("uuid", [][]byte{[]byte("org.hyperledger.fabric:GetMetadata")})
+	if res.Status != shim.OK {
+		fmt.Println("GetMetadata failed", string(res.Message))
+		return "", errors.New("GetMetadata error")
+	}
+	return string(res.Payload), nil
+}
@@ -0,0 +1,44 @@
+// Package verifier checks offline, without access to the ledger, the proofs returned by
+// GetTransactionProof that a transaction is part of the set recorded for a user
+package verifier
+
+import (
+	"fmt"
+	"strings"
+
+	"users/merkle"
+)
+
+// TransactionProof proof that the transaction Hash is the leaf at Proof.Index of the
+// Merkle tree over the transactions recorded for UserId, whose root was Root when the
+// tree had Proof.Size leaves
+type TransactionProof struct {
+	UserId string       `json:"user_id"`
+	Hash   string       `json:"hash"`
+	Root   string       `json:"root"`
+	Proof  merkle.Proof `json:"proof"`
+}
+
+// TransactionLeafHash returns the Merkle leaf of a transaction hash, taken in its
+// canonical form without 0x prefix and in lower case
+func TransactionLeafHash(hash string) []byte {
+	return merkle.LeafHash([]byte(strings.TrimPrefix(strings.ToLower(hash), "0x")))
+}
+
+// Verify checks that proof is consistent, its Hash leading to its Root. Use VerifyRoot
+// to also tie it to a root obtained from a trusted source
+func Verify(proof *TransactionProof) error {
+	if !merkle.Verify(TransactionLeafHash(proof.Hash), &proof.Proof, proof.Root) {
+		return fmt.Errorf("transaction %s is not included in root %s", proof.Hash, proof.Root)
+	}
+	return nil
+}
+
+// VerifyRoot checks proof against root, the trusted root of the transactions of the
+// user when they numbered proof.Proof.Size
+func VerifyRoot(proof *TransactionProof, root string) error {
+	if !strings.EqualFold(proof.Root, root) {
+		return fmt.Errorf("proof is for root %s, expected %s", proof.Root, root)
+	}
+	return Verify(proof)
+}
@@ -20,6 +20,11 @@ FABRIC_CFG_PATH=$PWD/configtx/
 if [ "$CC_SRC_LANGUAGE" = "go" -o "$CC_SRC_LANGUAGE" = "golang" ] ; then
 	CC_RUNTIME_LANGUAGE=golang
 	CC_SRC_PATH="../chaincode/${CHAINCODE_NAME}/"
+	# private data collections, when the chaincode defines any
+	CC_COLL_CONFIG=""
+	if [ -f "${CC_SRC_PATH}collections_config.json" ]; then
+		CC_COLL_CONFIG="--collections-config ${CC_SRC_PATH}collections_config.json"
+	fi
 
 	echo Vendoring Go dependencies ...
 	pushd ../chaincode/${CHAINCODE_NAME}
@@ -84,7 +89,7 @@ approveForMyOrg() {
   ORG=$1
   setGlobals $ORG
   set -x
-  peer lifecycle chaincode approveformyorg -o localhost:7050 --ordererTLSHostnameOverride $ORDERER_HOST --channelID $CHANNEL_NAME --name ${CHAINCODE_NAME} --version ${VERSION} --init-required --package-id ${PACKAGE_ID} --sequence ${VERSION} >&log.txt
+  peer lifecycle chaincode approveformyorg -o localhost:7050 --ordererTLSHostnameOverride $ORDERER_HOST --channelID $CHANNEL_NAME --name ${CHAINCODE_NAME} --version ${VERSION} --init-required --package-id ${PACKAGE_ID} --sequence ${VERSION} ${CC_COLL_CONFIG} >&log.txt
   set +x
   cat log.txt
   verifyResult $res "Chaincode definition approved on peer0.Org${ORG} on channel '$CHANNEL_NAME' failed"
@@ -106,7 +111,7 @@ checkCommitReadiness() {
     sleep $DELAY
     echo "Attempting to check the commit readiness of the chaincode definition on peer0.Org${ORG}, Retry after $DELAY seconds."
     set -x
-    peer lifecycle chaincode checkcommitreadiness --channelID $CHANNEL_NAME --name ${CHAINCODE_NAME} --version ${VERSION} --sequence ${VERSION} --output json --init-required >&log.txt
+    peer lifecycle chaincode checkcommitreadiness --channelID $CHANNEL_NAME --name ${CHAINCODE_NAME} --version ${VERSION} --sequence ${VERSION} ${CC_COLL_CONFIG} --output json --init-required >&log.txt
     res=$?
     set +x
     let rc=0
@@ -136,7 +141,7 @@ commitChaincodeDefinition() {
   # peer (if join was successful), let's supply it directly as we know
   # it using the "-o" option
   set -x
-  peer lifecycle chaincode commit -o localhost:7050 --ordererTLSHostnameOverride $ORDERER_HOST $ORDERER_CA --channelID $CHANNEL_NAME --name ${CHAINCODE_NAME} $PEER_CONN_PARMS --version ${VERSION} --sequence ${VERSION} --init-required >&log.txt
+  peer lifecycle chaincode commit -o localhost:7050 --ordererTLSHostnameOverride $ORDERER_HOST $ORDERER_CA --channelID $CHANNEL_NAME --name ${CHAINCODE_NAME} $PEER_CONN_PARMS --version ${VERSION} --sequence ${VERSION} ${CC_COLL_CONFIG} --init-required >&log.txt
   res=$?
   set +x
   cat log.txt
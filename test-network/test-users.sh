@@ -27,8 +27,11 @@ while [[ $# -ge 1 ]] ; do
     shift
     ;;
   2 ) # Invoke
-    peer chaincode invoke -o localhost:7050 -C mychannel -n $CHAINCODE_NAME --peerAddresses localhost:7051 -c '{"function":"CreateUser","Args":["1","Evan","evan@gmail.com"]}'
-    peer chaincode invoke -o localhost:7050 -C mychannel -n $CHAINCODE_NAME --peerAddresses localhost:7051 -c '{"function":"CreateUser","Args":["2","Amy","amy@gmail.com"]}'
+    # the name and email go in the transient map, off the ledger
+    EVAN=$(echo -n '{"name":"Evan","email":"evan@gmail.com"}' | base64 | tr -d '\n')
+    AMY=$(echo -n '{"name":"Amy","email":"amy@gmail.com"}' | base64 | tr -d '\n')
+    peer chaincode invoke -o localhost:7050 -C mychannel -n $CHAINCODE_NAME --peerAddresses localhost:7051 -c '{"function":"CreateUser","Args":["1"]}' --transient "{\"personal_data\":\"$EVAN\"}"
+    peer chaincode invoke -o localhost:7050 -C mychannel -n $CHAINCODE_NAME --peerAddresses localhost:7051 -c '{"function":"CreateUser","Args":["2"]}' --transient "{\"personal_data\":\"$AMY\"}"
     peer chaincode invoke -o localhost:7050 -C mychannel -n $CHAINCODE_NAME --peerAddresses localhost:7051 -c '{"function":"DeleteUser","Args":["2"]}'
     shift
     ;;